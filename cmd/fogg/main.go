@@ -0,0 +1,37 @@
+// Command fogg provides tooling for struct tags.
+//
+// Usage:
+//
+//	fogg lsp    run the language server on stdin and stdout
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kuzgoga/fogg/internal/lsp"
+)
+
+const usage = `usage: fogg <command>
+
+commands:
+  lsp    run the language server on stdin and stdout
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "lsp":
+		if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "fogg lsp: %s\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "fogg: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
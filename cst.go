@@ -0,0 +1,448 @@
+package fogg
+
+import (
+	"strings"
+)
+
+// Span is a half-open range of byte offsets in the parsed source. Nodes
+// created or changed by edits keep the span of the original source, or a zero
+// span when they did not exist in it.
+type Span struct {
+	Start int
+	End   int
+}
+
+// File is a lossless syntax tree of a whole struct tag. Printing it with
+// String reproduces the parsed source byte for byte.
+type File struct {
+	Span
+	Tags     []*TagNode
+	Trailing string
+}
+
+// TagNode is a single `name:"..."` tag. Leading holds the whitespace before it.
+type TagNode struct {
+	Span
+	Leading string
+	Name    string
+	Items   []*ItemNode
+}
+
+// ItemNode is a `;` separated item of a tag value. An item without Param and
+// Option is empty, as produced by `;;` or a trailing `;`.
+type ItemNode struct {
+	Span
+	Leading  string
+	Trailing string
+	Param    *ParamNode
+	Option   *OptionNode
+}
+
+// ParamNode is a `key:value` item. Its value is made of `,` separated args.
+type ParamNode struct {
+	Span
+	Key         string
+	BeforeColon string
+	AfterColon  string
+	Args        []*ArgNode
+}
+
+type OptionNode struct {
+	Span
+	Raw string
+}
+
+type ArgNode struct {
+	Span
+	Raw string
+}
+
+// ParseFile parses a whole struct tag into a File.
+func ParseFile(content string) (*File, error) {
+	tokens, err := Tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{Span: Span{0, len(content)}}
+	builder := cstBuilder{content: content, tokens: tokens}
+
+	for !builder.done() {
+		leading := builder.whitespace()
+		if builder.done() {
+			file.Trailing = leading
+			break
+		}
+		tag := builder.tag()
+		tag.Leading = leading
+		file.Tags = append(file.Tags, tag)
+	}
+
+	return file, nil
+}
+
+func (file *File) String() string {
+	var builder strings.Builder
+	for _, tag := range file.Tags {
+		tag.write(&builder)
+	}
+	builder.WriteString(file.Trailing)
+	return builder.String()
+}
+
+func (file *File) Tag(name string) *TagNode {
+	for _, tag := range file.Tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+func (tag *TagNode) String() string {
+	var builder strings.Builder
+	tag.write(&builder)
+	return builder.String()
+}
+
+func (tag *TagNode) write(builder *strings.Builder) {
+	builder.WriteString(tag.Leading)
+	builder.WriteString(tag.Name)
+	builder.WriteByte(tagNameDelimiter)
+	builder.WriteByte(tagBacktick)
+	for i, item := range tag.Items {
+		if i > 0 {
+			builder.WriteByte(subtagDelimiter)
+		}
+		item.write(builder)
+	}
+	builder.WriteByte(tagBacktick)
+}
+
+// Param returns the first param with the given name.
+func (tag *TagNode) Param(name string) *ParamNode {
+	for _, item := range tag.Items {
+		if item.Param != nil && item.Param.Name() == name {
+			return item.Param
+		}
+	}
+	return nil
+}
+
+// Option returns the first option with the given name.
+func (tag *TagNode) Option(name string) *OptionNode {
+	for _, item := range tag.Items {
+		if item.Option != nil && item.Option.Name() == name {
+			return item.Option
+		}
+	}
+	return nil
+}
+
+// SetParam changes the value of the named param, leaving the rest of the tag
+// untouched, or appends the param when the tag has none.
+func (tag *TagNode) SetParam(name, value string) {
+	if param := tag.Param(name); param != nil {
+		param.SetValue(value)
+		return
+	}
+
+	param := &ParamNode{Key: name}
+	param.SetValue(value)
+	tag.appendItem(&ItemNode{Param: param})
+}
+
+// AddOption appends an option unless the tag already has it.
+func (tag *TagNode) AddOption(name string) {
+	if tag.Option(name) == nil {
+		tag.appendItem(&ItemNode{Option: &OptionNode{Raw: name}})
+	}
+}
+
+// Remove deletes every param and option with the given name, together with
+// their separators, and reports whether anything was removed.
+func (tag *TagNode) Remove(name string) bool {
+	items := tag.Items[:0]
+	for _, item := range tag.Items {
+		if item.Name() != name || item.Empty() {
+			items = append(items, item)
+		}
+	}
+
+	removed := len(items) != len(tag.Items)
+	tag.Items = items
+	if len(tag.Items) == 0 {
+		tag.Items = append(tag.Items, &ItemNode{})
+	}
+	return removed
+}
+
+func (tag *TagNode) appendItem(item *ItemNode) {
+	last := len(tag.Items) - 1
+	switch {
+	case last == -1:
+		tag.Items = append(tag.Items, item)
+	case tag.Items[last].Empty() && len(tag.Items) == 1:
+		tag.Items[last] = item
+	case tag.Items[last].Empty():
+		// Keep a trailing `;` after the new item
+		tag.Items = append(tag.Items[:last], item, tag.Items[last])
+	default:
+		tag.Items = append(tag.Items, item)
+	}
+}
+
+func (item *ItemNode) write(builder *strings.Builder) {
+	builder.WriteString(item.Leading)
+	if item.Param != nil {
+		item.Param.write(builder)
+	} else if item.Option != nil {
+		builder.WriteString(item.Option.Raw)
+	}
+	builder.WriteString(item.Trailing)
+}
+
+func (item *ItemNode) Empty() bool {
+	return item.Param == nil && item.Option == nil
+}
+
+// Name returns the name of the param or option of the item.
+func (item *ItemNode) Name() string {
+	if item.Param != nil {
+		return item.Param.Name()
+	} else if item.Option != nil {
+		return item.Option.Name()
+	}
+	return ""
+}
+
+func (param *ParamNode) write(builder *strings.Builder) {
+	builder.WriteString(param.Key)
+	builder.WriteString(param.BeforeColon)
+	builder.WriteByte(tagNameDelimiter)
+	builder.WriteString(param.AfterColon)
+	builder.WriteString(param.RawValue())
+}
+
+// Name returns the unescaped key of the param.
+func (param *ParamNode) Name() string {
+	return unescapeText(param.Key, tagTextEscapes)
+}
+
+// RawValue returns the value of the param as written in the source.
+func (param *ParamNode) RawValue() string {
+	raws := make([]string, 0, len(param.Args))
+	for _, arg := range param.Args {
+		raws = append(raws, arg.Raw)
+	}
+	return strings.Join(raws, string(subtagArgsDelimiter))
+}
+
+// Value returns the value of the param as Parse reports it.
+func (param *ParamNode) Value() string {
+	return unquoteParamValue(unescapeText(param.RawValue(), tagTextEscapes))
+}
+
+// SetValue replaces the value of the param, quoting and escaping it when
+// needed so that Parse reports exactly value back.
+func (param *ParamNode) SetValue(value string) {
+	param.SetRawValue(quoteParamValue(value))
+}
+
+// SetRawValue replaces the value of the param with raw, which is written to
+// the tag as is.
+func (param *ParamNode) SetRawValue(raw string) {
+	param.Args = param.Args[:0]
+	for _, arg := range splitArgs(raw) {
+		param.Args = append(param.Args, &ArgNode{Raw: arg})
+	}
+}
+
+// Name returns the option as Parse reports it.
+func (option *OptionNode) Name() string {
+	return unescapeText(option.Raw, tagTextEscapes)
+}
+
+// Value returns the arg without escape sequences and surrounding quotes.
+func (arg *ArgNode) Value() string {
+	return unquoteParamValue(unescapeText(arg.Raw, tagTextEscapes))
+}
+
+func (param *ParamNode) String() string {
+	var builder strings.Builder
+	param.write(&builder)
+	return builder.String()
+}
+
+// splitArgs splits a raw param value on the commas that are not escaped or
+// enclosed in quotes.
+func splitArgs(raw string) []string {
+	var (
+		args        []string
+		quotesStack []byte
+	)
+
+	start := 0
+	for pos := 0; pos < len(raw); pos++ {
+		char := raw[pos]
+		switch {
+		case char == escapeBackslash && pos+1 < len(raw) && tagTextEscapes[raw[pos+1]]:
+			pos++
+		case subtagQuotes[char]:
+			if depth := len(quotesStack); depth > 0 && quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
+			} else {
+				quotesStack = append(quotesStack, char)
+			}
+		case char == subtagArgsDelimiter && len(quotesStack) == 0:
+			args = append(args, raw[start:pos])
+			start = pos + 1
+		}
+	}
+	return append(args, raw[start:])
+}
+
+func quoteParamValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, `;'"\`) && strings.TrimSpace(value) == value {
+		return value
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value) + 2)
+	builder.WriteByte('\'')
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case escapeBackslash, '\'', '"':
+			builder.WriteByte(escapeBackslash)
+		}
+		builder.WriteByte(value[i])
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+type cstBuilder struct {
+	content string
+	tokens  []Token
+	pos     int
+}
+
+func (builder *cstBuilder) done() bool {
+	return builder.pos >= len(builder.tokens)
+}
+
+func (builder *cstBuilder) peek() Token {
+	return builder.tokens[builder.pos]
+}
+
+func (builder *cstBuilder) whitespace() string {
+	start := builder.pos
+	for !builder.done() && builder.peek().Kind == TokenWhitespace {
+		builder.pos++
+	}
+	return builder.source(start, builder.pos)
+}
+
+// source returns the text covered by tokens[from:to].
+func (builder *cstBuilder) source(from, to int) string {
+	if from >= to {
+		return ""
+	}
+	return builder.content[builder.tokens[from].Start:builder.tokens[to-1].End]
+}
+
+func (builder *cstBuilder) tag() *TagNode {
+	tag := &TagNode{Span: Span{builder.peek().Start, 0}}
+
+	if builder.peek().Kind == TokenTagName {
+		tag.Name = builder.source(builder.pos, builder.pos+1)
+		builder.pos++
+	}
+	// Skip the colon and the opening quote, the lexer guarantees both
+	builder.pos += 2
+
+	itemStart := builder.pos
+	for {
+		token := builder.peek()
+		switch token.Kind {
+		case TokenItemSeparator:
+			tag.Items = append(tag.Items, builder.item(itemStart, builder.pos, token.Start))
+			itemStart = builder.pos + 1
+		case TokenQuote:
+			tag.Items = append(tag.Items, builder.item(itemStart, builder.pos, token.Start))
+			tag.End = token.End
+			builder.pos++
+			return tag
+		}
+		builder.pos++
+	}
+}
+
+// item builds the item made of tokens[from:to] that ends at offset end.
+func (builder *cstBuilder) item(from, to, end int) *ItemNode {
+	start := end
+	if from < to {
+		start = builder.tokens[from].Start
+	}
+	item := &ItemNode{Span: Span{start, end}}
+
+	if from < to && builder.tokens[from].Kind == TokenWhitespace {
+		item.Leading = builder.source(from, from+1)
+		from++
+	}
+	if from < to && builder.tokens[to-1].Kind == TokenWhitespace {
+		item.Trailing = builder.source(to-1, to)
+		to--
+	}
+	if from == to {
+		return item
+	}
+
+	colon := -1
+	for i := from; i < to; i++ {
+		if builder.tokens[i].Kind == TokenColon {
+			colon = i
+			break
+		}
+	}
+
+	span := Span{builder.tokens[from].Start, builder.tokens[to-1].End}
+	if colon == -1 {
+		item.Option = &OptionNode{Span: span, Raw: builder.source(from, to)}
+		return item
+	}
+
+	param := &ParamNode{Span: span}
+	keyEnd := colon
+	if keyEnd > from && builder.tokens[keyEnd-1].Kind == TokenWhitespace {
+		param.BeforeColon = builder.source(keyEnd-1, keyEnd)
+		keyEnd--
+	}
+	param.Key = builder.source(from, keyEnd)
+
+	valueStart := colon + 1
+	if valueStart < to && builder.tokens[valueStart].Kind == TokenWhitespace {
+		param.AfterColon = builder.source(valueStart, valueStart+1)
+		valueStart++
+	}
+
+	argStart := builder.tokens[colon].End + len(param.AfterColon)
+	for i := valueStart; i <= to; i++ {
+		if i == to || builder.tokens[i].Kind == TokenArgSeparator {
+			argEnd := span.End
+			if i < to {
+				argEnd = builder.tokens[i].Start
+			}
+			param.Args = append(param.Args, &ArgNode{
+				Span: Span{argStart, argEnd},
+				Raw:  builder.content[argStart:argEnd],
+			})
+			if i < to {
+				argStart = builder.tokens[i].End
+			}
+		}
+	}
+
+	item.Param = param
+	return item
+}
@@ -0,0 +1,178 @@
+package fogg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFileRoundTrip(t *testing.T) {
+	tags := []string{
+		``,
+		`   `,
+		`gorm:""`,
+		`gorm:"default:'something';not null"`,
+		`  json:"-,"   gorm:"check:,name <> 'jinzhu';index:idx,sort:desc"  `,
+		`ui:"label:Время создания; readonly; datatype:datetime;"`,
+		`gorm:"default:'a\'b';comment:\\;size:256\"" xml:"a\ b"`,
+		`gorm:" key : value , other ;;  ; 'a:b'"` + "\t" + `json:"id"`,
+		`gorm:"index:,unique;foreignKey:Customer\"Id;default:'ui\\path'"`,
+	}
+
+	for _, tag := range tags {
+		file, err := ParseFile(tag)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tag, err)
+			continue
+		}
+
+		if printed := file.String(); printed != tag {
+			t.Errorf("expected %q to be printed back, got %q", tag, printed)
+		}
+	}
+}
+
+func TestParseFileNodes(t *testing.T) {
+	const tag = `json:"id" gorm:"index:idx, unique ; not null"`
+
+	file, err := ParseFile(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(file.Tags) != 2 {
+		t.Fatalf("expected 2 tags, got %d", len(file.Tags))
+	}
+
+	gorm := file.Tag("gorm")
+	if gorm == nil || gorm.Leading != " " || gorm.Span != (Span{10, 45}) {
+		t.Fatalf("unexpected gorm tag %+v", gorm)
+	}
+	if len(gorm.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(gorm.Items))
+	}
+
+	index := gorm.Param("index")
+	if index == nil || index.Span != (Span{16, 33}) {
+		t.Fatalf("unexpected index param %+v", index)
+	}
+	if index.Value() != "idx, unique" || gorm.Items[0].Trailing != " " {
+		t.Errorf("unexpected index value %q", index.Value())
+	}
+
+	args := make([]string, 0, len(index.Args))
+	for _, arg := range index.Args {
+		args = append(args, arg.Raw)
+		if tag[arg.Start:arg.End] != arg.Raw {
+			t.Errorf("span %v does not match arg %q", arg.Span, arg.Raw)
+		}
+	}
+	if !slices.Equal(args, []string{"idx", " unique"}) {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	notNull := gorm.Option("not null")
+	if notNull == nil || tag[notNull.Start:notNull.End] != "not null" {
+		t.Errorf("unexpected option %+v", notNull)
+	}
+}
+
+func TestEditParamPreservesRest(t *testing.T) {
+	const tag = `json:"id"  gorm:" column:id ;default:'x';  index:,unique;"`
+
+	file, err := ParseFile(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	gorm := file.Tag("gorm")
+	gorm.SetParam("default", `it's "a;b"`)
+	gorm.SetParam("size", "64")
+	gorm.AddOption("not null")
+	gorm.Remove("column")
+
+	const expected = `json:"id"  gorm:"default:'it\'s \"a;b\"';  index:,unique;size:64;not null;"`
+	if printed := file.String(); printed != expected {
+		t.Errorf("expected %s, got %s", expected, printed)
+	}
+
+	storage, err := Parse(file.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if value := storage.GetTag("gorm").GetParam("default").Value; value != `it's "a;b"` {
+		t.Errorf("unexpected default value %q", value)
+	}
+	if storage.GetTag("gorm").HasParam("column") {
+		t.Errorf("expected column param to be removed")
+	}
+}
+
+func TestEditEmptyTag(t *testing.T) {
+	file, err := ParseFile(`gorm:""`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	gorm := file.Tag("gorm")
+	gorm.AddOption("primaryKey")
+	gorm.AddOption("primaryKey")
+	if printed := file.String(); printed != `gorm:"primaryKey"` {
+		t.Errorf("unexpected tag %s", printed)
+	}
+
+	if !gorm.Remove("primaryKey") || file.String() != `gorm:""` {
+		t.Errorf("unexpected tag %s", file.String())
+	}
+	if gorm.Remove("primaryKey") {
+		t.Errorf("expected nothing to be removed")
+	}
+}
+
+func TestParamValueMatchesParse(t *testing.T) {
+	const tag = `gorm:"default:'ui\\path';foreignKey:Customer\"Id;index:,unique;check:x\;y"`
+
+	file, err := ParseFile(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	storage, err := Parse(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for name, param := range storage.GetTag("gorm").GetParams() {
+		node := file.Tag("gorm").Param(name)
+		if node == nil {
+			t.Errorf("param %s is missing", name)
+		} else if node.Value() != param.Value {
+			t.Errorf("param %s: expected %q, got %q", name, param.Value, node.Value())
+		}
+	}
+}
+
+func TestQuoteParamValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"a,b", "a,b"},
+		{"", "''"},
+		{" padded ", "' padded '"},
+		{`a;b`, `'a;b'`},
+		{`C:\dir`, `'C:\\dir'`},
+		{`say "hi"`, `'say \"hi\"'`},
+	}
+
+	for _, test := range tests {
+		if quoted := quoteParamValue(test.value); quoted != test.expected {
+			t.Errorf("quoteParamValue(%q) = %q; want %q", test.value, quoted, test.expected)
+		}
+	}
+}
+
+func TestParseFileError(t *testing.T) {
+	if _, err := ParseFile(`gorm:"default:'x"`); err == nil || err.Error() != unclosedBacktickErr {
+		t.Errorf("expected error `%s`, got `%s`", unclosedBacktickErr, err)
+	}
+}
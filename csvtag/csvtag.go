@@ -0,0 +1,422 @@
+// Package csvtag converts slices of structs to and from CSV with encoding/csv,
+// using csv tags to name and format columns. Tags are written in the GORM style:
+// the column name comes first, followed by the `format`, `default` and `order`
+// params and the `omitempty` option:
+//
+//	type Order struct {
+//		Customer string    `csv:"Customer Name;order:1"`
+//		Total    float64   `csv:"Total;format:'%.2f'"`
+//		Shipped  time.Time `csv:"Shipped At;format:'2006-01-02';default:'n/a'"`
+//		Note     string    `csv:"Note;omitempty"`
+//	}
+//
+// The format of times is a time layout, RFC 3339 by default, and the format of
+// other values is a fmt verb used when writing. Numbers are read back with the
+// same verb, so their format must hold exactly one verb of their kind and no
+// `#` flag; other fields ignore the format when read. The default is the text
+// written for zero values and read back as zero values, as are empty cells of
+// columns with a default or omitempty. Nil pointers are written as empty cells
+// and read back from them.
+package csvtag
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuzgoga/fogg"
+	"github.com/kuzgoga/fogg/internal/convert"
+)
+
+const (
+	tagName         = "csv"
+	skipTag         = "-"
+	omitEmptyOption = "omitempty"
+	formatParam     = "format"
+	defaultParam    = "default"
+	orderParam      = "order"
+	itemsSeparator  = ","
+	pairSeparator   = ":"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type columnInfo struct {
+	index     int
+	name      string
+	format    string
+	scan      string
+	zero      string
+	hasZero   bool
+	omitEmpty bool
+	order     int
+	hasOrder  bool
+}
+
+// Marshal writes a header and a record for every struct of rows, which is a
+// slice of structs or of pointers to structs.
+func Marshal(w *csv.Writer, rows any) error {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Slice || !isStruct(value.Type().Elem()) {
+		return errors.New(fmt.Sprintf(notSliceErr, reflect.TypeOf(rows)))
+	}
+
+	structType := value.Type().Elem()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	columns, err := structColumns(structType)
+	if err != nil {
+		return err
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for i := 0; i < value.Len(); i++ {
+		row := reflect.Indirect(value.Index(i))
+		for j, column := range columns {
+			if !row.IsValid() {
+				record[j] = ""
+			} else if record[j], err = column.formatValue(row.Field(column.index)); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// Unmarshal reads a header and the records following it into rows, which
+// points to a slice of structs or of pointers to structs. Columns are matched
+// to fields by name, exactly or else ignoring case, unknown columns are
+// ignored. Rows with cells which cannot be parsed are skipped and reported
+// together as Errors.
+func Unmarshal(r *csv.Reader, rows any) error {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Slice || !isStruct(value.Elem().Type().Elem()) {
+		return errors.New(fmt.Sprintf(notSlicePointerErr, reflect.TypeOf(rows)))
+	}
+	slice := value.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	columns, err := structColumns(structType)
+	if err != nil {
+		return err
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+	positions, err := matchHeader(header, columns)
+	if err != nil {
+		return err
+	}
+
+	var rowErrors Errors
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		row := reflect.New(structType).Elem()
+		valid := true
+		for i, column := range columns {
+			position := positions[i]
+			if position < 0 || position >= len(record) {
+				continue
+			}
+			if err := column.parseValue(row.Field(column.index), record[position]); err != nil {
+				line, _ := r.FieldPos(position)
+				rowErrors = append(rowErrors, &RowError{Line: line, Column: column.name, Err: err})
+				valid = false
+			}
+		}
+
+		if !valid {
+			continue
+		}
+		if elemType.Kind() == reflect.Pointer {
+			row = row.Addr()
+		}
+		slice.Set(reflect.Append(slice, row))
+	}
+
+	if len(rowErrors) != 0 {
+		return rowErrors
+	}
+	return nil
+}
+
+func isStruct(elemType reflect.Type) bool {
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	return elemType.Kind() == reflect.Struct
+}
+
+// structColumns lists the columns of a struct type, those with an order first.
+func structColumns(structType reflect.Type) ([]columnInfo, error) {
+	columns := []columnInfo{}
+	names := map[string]bool{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		content := field.Tag.Get(tagName)
+		if !field.IsExported() || content == skipTag {
+			continue
+		}
+
+		tag, err := fogg.ParseSubtag(content, true)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(invalidTagErr, field.Name, err))
+		}
+		if field.Type != timeType && !convert.Supported(field.Type) {
+			return nil, errors.New(fmt.Sprintf(unsupportedTypeErr, field.Name, field.Type))
+		}
+
+		column := columnInfo{
+			index:     i,
+			name:      tag.GetValue(),
+			format:    tag.GetParamOr(formatParam, ""),
+			omitEmpty: tag.HasOption(omitEmptyOption),
+		}
+		if column.format != "" && isNumber(field.Type) {
+			if column.scan = scanFormat(column.format, field.Type); column.scan == "" {
+				return nil, errors.New(fmt.Sprintf(unreadableFormatErr, column.format, field.Name))
+			}
+		}
+		if column.name == "" || column.name == omitEmptyOption {
+			column.name = field.Name
+		}
+		if param := tag.GetParam(defaultParam); param != nil {
+			column.zero, column.hasZero = param.Value, true
+		}
+		if param := tag.GetParam(orderParam); param != nil {
+			if column.order, err = strconv.Atoi(param.Value); err != nil {
+				return nil, errors.New(fmt.Sprintf(invalidOrderErr, param.Value, field.Name))
+			}
+			column.hasOrder = true
+		}
+
+		if names[column.name] {
+			return nil, errors.New(fmt.Sprintf(duplicatedColumnErr, column.name))
+		}
+		names[column.name] = true
+		columns = append(columns, column)
+	}
+
+	sort.SliceStable(columns, func(i, j int) bool {
+		if columns[i].hasOrder && columns[j].hasOrder {
+			return columns[i].order < columns[j].order
+		}
+		return columns[i].hasOrder && !columns[j].hasOrder
+	})
+	return columns, nil
+}
+
+// matchHeader returns the position of every column in the header, -1 for
+// missing columns which may be empty.
+func matchHeader(header []string, columns []columnInfo) ([]int, error) {
+	positions := make([]int, len(columns))
+	for i, column := range columns {
+		positions[i] = -1
+		for j, name := range header {
+			if name == column.name {
+				positions[i] = j
+				break
+			} else if positions[i] < 0 && strings.EqualFold(name, column.name) {
+				positions[i] = j
+			}
+		}
+
+		if positions[i] < 0 && !column.omitEmpty && !column.hasZero {
+			return nil, errors.New(fmt.Sprintf(missingColumnErr, column.name))
+		}
+	}
+	return positions, nil
+}
+
+func (column *columnInfo) formatValue(value reflect.Value) (string, error) {
+	if value.IsZero() {
+		if column.hasZero {
+			return column.zero, nil
+		} else if column.omitEmpty {
+			return "", nil
+		}
+	}
+
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == timeType {
+		layout := column.format
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return value.Interface().(time.Time).Format(layout), nil
+	}
+	if column.format != "" {
+		return fmt.Sprintf(column.format, value.Interface()), nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if value.Kind() == reflect.Slice {
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(value.Index(i).Interface())
+		}
+		return strings.Join(items, itemsSeparator), nil
+	}
+	if value.Kind() == reflect.Map {
+		keys := value.MapKeys()
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			entries[i] = fmt.Sprint(key.Interface()) + pairSeparator + fmt.Sprint(value.MapIndex(key).Interface())
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, itemsSeparator), nil
+	}
+	return fmt.Sprint(value.Interface()), nil
+}
+
+func (column *columnInfo) parseValue(value reflect.Value, text string) error {
+	if (column.hasZero && text == column.zero) || (text == "" && (column.hasZero || column.omitEmpty || value.Kind() == reflect.Pointer)) {
+		return nil
+	}
+
+	target := value
+	if target.Kind() == reflect.Pointer {
+		target = reflect.New(value.Type().Elem()).Elem()
+	}
+
+	var err error
+	switch {
+	case column.scan != "":
+		err = scanNumber(target, text, column.scan)
+	case target.Type() == timeType && column.format != "":
+		var parsed time.Time
+		if parsed, err = time.Parse(column.format, text); err == nil {
+			target.Set(reflect.ValueOf(parsed))
+		}
+	case target.Kind() >= reflect.Int && target.Kind() <= reflect.Int64 && target.Type() != durationType:
+		var parsed int64
+		if parsed, err = strconv.ParseInt(text, 10, target.Type().Bits()); err == nil {
+			target.SetInt(parsed)
+		}
+	case target.Kind() >= reflect.Uint && target.Kind() <= reflect.Uint64:
+		var parsed uint64
+		if parsed, err = strconv.ParseUint(text, 10, target.Type().Bits()); err == nil {
+			target.SetUint(parsed)
+		}
+	default:
+		err = convert.Set(target, text, itemsSeparator, pairSeparator)
+	}
+	if err != nil {
+		return err
+	}
+
+	if value.Kind() == reflect.Pointer {
+		value.Set(target.Addr())
+	}
+	return nil
+}
+
+func isNumber(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() >= reflect.Int && fieldType.Kind() <= reflect.Float64 && fieldType.Kind() != reflect.Uintptr
+}
+
+// scanFormat turns the format of a number into the format scanning it back,
+// dropping the flags, widths and precisions fmt cannot scan. It returns an empty
+// string for formats which do not hold exactly one verb of the number kind.
+func scanFormat(format string, fieldType reflect.Type) string {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	verbs := "bodxXv"
+	if fieldType.Kind() == reflect.Float32 || fieldType.Kind() == reflect.Float64 {
+		verbs = "beEfFgGxXv"
+	}
+
+	var builder strings.Builder
+	count := 0
+	for i := 0; i < len(format); i++ {
+		builder.WriteByte(format[i])
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			builder.WriteByte('%')
+			i++
+			continue
+		}
+
+		i++
+		for i < len(format) && strings.IndexByte("+- 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) || strings.IndexByte(verbs, format[i]) < 0 {
+			return ""
+		}
+		builder.WriteByte(format[i])
+		count++
+	}
+	if count != 1 {
+		return ""
+	}
+	return builder.String()
+}
+
+// scanNumber reads text into target with format, which must consume all of it
+// but the spaces padding the number.
+func scanNumber(target reflect.Value, text string, format string) error {
+	reader := strings.NewReader(text)
+	if _, err := fmt.Fscanf(reader, format, target.Addr().Interface()); err != nil {
+		return err
+	}
+	if rest, _ := io.ReadAll(reader); strings.TrimSpace(string(rest)) != "" {
+		return errors.New(fmt.Sprintf(unscannedTextErr, text, format))
+	}
+	return nil
+}
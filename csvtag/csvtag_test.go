@@ -0,0 +1,205 @@
+package csvtag
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type order struct {
+	ID       int       `csv:"ID;order:1"`
+	Customer string    `csv:"Customer Name;order:2"`
+	Total    float64   `csv:"Total;format:'%.2f'"`
+	Shipped  time.Time `csv:"Shipped At;format:'2006-01-02';default:'n/a'"`
+	Note     string    `csv:"Note;omitempty"`
+	Tags     []string  `csv:"Tags"`
+	Quantity *uint     `csv:"Quantity"`
+	Address  netip.Addr
+	Internal string `csv:"-"`
+}
+
+func uintPointer(value uint) *uint {
+	return &value
+}
+
+var orders = []order{
+	{ID: 1, Customer: "Ann, Ltd", Total: 12.5, Shipped: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"a", "b"}, Quantity: uintPointer(2), Address: netip.MustParseAddr("10.0.0.1")},
+	{ID: 2, Customer: "Bob", Total: 3, Note: "gift", Address: netip.MustParseAddr("::1")},
+}
+
+const ordersCSV = `ID,Customer Name,Total,Shipped At,Note,Tags,Quantity,Address
+1,"Ann, Ltd",12.50,2024-03-01,,"a,b",2,10.0.0.1
+2,Bob,3.00,n/a,gift,,,::1
+`
+
+func TestMarshal(t *testing.T) {
+	var builder strings.Builder
+	if err := Marshal(csv.NewWriter(&builder), orders); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if builder.String() != ordersCSV {
+		t.Errorf("got:\n%s\nexpected:\n%s", builder.String(), ordersCSV)
+	}
+
+	builder.Reset()
+	if err := Marshal(csv.NewWriter(&builder), []*order{&orders[1], nil}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lines := strings.Split(builder.String(), "\n"); len(lines) != 4 || lines[2] != ",,,,,,," {
+		t.Errorf("unexpected output %q", builder.String())
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var parsed []order
+	if err := Unmarshal(csv.NewReader(strings.NewReader(ordersCSV)), &parsed); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := append([]order{}, orders...)
+	expected[1].Tags = []string{}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("got %+v, expected %+v", parsed, expected)
+	}
+}
+
+func TestUnmarshalHeaderMatching(t *testing.T) {
+	const content = "extra,customer name,Total,id,Tags,Quantity,Address\n" +
+		"x,Ann,1.5,007,,,::1\n"
+
+	var parsed []*order
+	if err := Unmarshal(csv.NewReader(strings.NewReader(content)), &parsed); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(parsed) != 1 || parsed[0].ID != 7 || parsed[0].Customer != "Ann" || !parsed[0].Shipped.IsZero() {
+		t.Errorf("unexpected rows %+v", parsed)
+	}
+
+	err := Unmarshal(csv.NewReader(strings.NewReader("ID,Customer Name\n")), &parsed)
+	if err == nil || err.Error() != `missing column "Total"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUnmarshalRowErrors(t *testing.T) {
+	const content = "ID,Customer Name,Total,Shipped At,Tags,Quantity,Address\n" +
+		"1,Ann,1,2024-01-01,,,::1\n" +
+		"x,Bob,1,yesterday,,,::1\n" +
+		"3,\"Multi\nline\",1,n/a,,-1,::1\n" +
+		"4,Dan,1,,,,::1\n"
+
+	var parsed []order
+	err := Unmarshal(csv.NewReader(strings.NewReader(content)), &parsed)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+
+	expected := []struct {
+		line   int
+		column string
+	}{
+		{3, "ID"},
+		{3, "Shipped At"},
+		{5, "Quantity"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for i, test := range expected {
+		if errs[i].Line != test.line || errs[i].Column != test.column {
+			t.Errorf("error %d: got %v", i, errs[i])
+		}
+	}
+	if message := errs[0].Error(); message != `line 3, column "ID": strconv.ParseInt: parsing "x": invalid syntax` {
+		t.Errorf("unexpected message %s", message)
+	}
+
+	if len(parsed) != 2 || parsed[0].ID != 1 || parsed[1].ID != 4 {
+		t.Errorf("expected valid rows to be kept, got %+v", parsed)
+	}
+}
+
+type formatted struct {
+	Hex     int      `csv:"Hex;format:'%x'"`
+	Padded  float64  `csv:"Padded;format:'%05.1f'"`
+	Signed  *int8    `csv:"Signed;format:'%+d'"`
+	Percent float32  `csv:"Percent;format:'%-6.1f%%'"`
+	Octal   uint16   `csv:"Octal;format:'0o%o'"`
+	Labels  []string `csv:"Labels;format:'%v'"`
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	signed := int8(-3)
+	rows := []formatted{{Hex: 255, Padded: 2.5, Signed: &signed, Percent: 12.5, Octal: 8, Labels: []string{"a"}}}
+
+	var builder strings.Builder
+	if err := Marshal(csv.NewWriter(&builder), rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const expected = "Hex,Padded,Signed,Percent,Octal,Labels\nff,002.5,-3,12.5  %,0o10,[a]\n"
+	if builder.String() != expected {
+		t.Errorf("got %q, expected %q", builder.String(), expected)
+	}
+
+	var parsed []formatted
+	if err := Unmarshal(csv.NewReader(strings.NewReader(builder.String())), &parsed); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rows[0].Labels = []string{"[a]"}
+	if !reflect.DeepEqual(parsed, rows) {
+		t.Errorf("got %+v, expected %+v", parsed, rows)
+	}
+
+	err := Unmarshal(csv.NewReader(strings.NewReader("Hex,Padded,Signed,Percent,Octal,Labels\nffz,1,1,1%,0o1,\n")), &parsed)
+	if err == nil || err.Error() != `line 2, column "Hex": "ffz" does not match format "%x"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	var orders []order
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{Marshal(csv.NewWriter(&strings.Builder{}), order{}), `expected a slice of structs, got csvtag.order`},
+		{Unmarshal(csv.NewReader(strings.NewReader("")), orders), `expected a pointer to a slice of structs, got []csvtag.order`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A int `csv:"A;order:first"`
+		}{}), `invalid order "first" of field A`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A int `csv:"A"`
+			B int `csv:"A"`
+		}{}), `duplicated column "A"`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A func() `csv:"A"`
+		}{}), `field A has unsupported type func()`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A int `csv:"A;format:'%d"`
+		}{}), `invalid csv tag of field A: unclosed backtick in tag`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A int `csv:"A;format:'%#x'"`
+		}{}), `format "%#x" of field A cannot be read back`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A int `csv:"A;format:'%.2f'"`
+		}{}), `format "%.2f" of field A cannot be read back`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A float64 `csv:"A;format:'%f/%f'"`
+		}{}), `format "%f/%f" of field A cannot be read back`},
+		{Marshal(csv.NewWriter(&strings.Builder{}), []struct {
+			A *uint `csv:"A;format:'total'"`
+		}{}), `format "total" of field A cannot be read back`},
+	}
+
+	for _, test := range tests {
+		if test.err == nil || test.err.Error() != test.expected {
+			t.Errorf("got error `%v`, expected `%s`", test.err, test.expected)
+		}
+	}
+}
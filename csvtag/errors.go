@@ -0,0 +1,45 @@
+package csvtag
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	notSliceErr         string = `expected a slice of structs, got %s`
+	notSlicePointerErr  string = `expected a pointer to a slice of structs, got %s`
+	invalidTagErr       string = `invalid csv tag of field %s: %s`
+	invalidOrderErr     string = `invalid order "%s" of field %s`
+	unsupportedTypeErr  string = `field %s has unsupported type %s`
+	duplicatedColumnErr string = `duplicated column "%s"`
+	missingColumnErr    string = `missing column "%s"`
+	unreadableFormatErr string = `format "%s" of field %s cannot be read back`
+	unscannedTextErr    string = `"%s" does not match format "%s"`
+	rowErr              string = `line %d, column "%s": %s`
+)
+
+// RowError reports a cell which cannot be parsed into its field.
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (err *RowError) Error() string {
+	return fmt.Sprintf(rowErr, err.Line, err.Column, err.Err)
+}
+
+func (err *RowError) Unwrap() error {
+	return err.Err
+}
+
+// Errors holds the errors of every skipped row.
+type Errors []*RowError
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
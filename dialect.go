@@ -0,0 +1,156 @@
+package fogg
+
+import (
+	"slices"
+	"strings"
+)
+
+// dialects maps tag names to parsers of tags which are not written in the GORM
+// style, used with WithDialects. Other tags are parsed by ParseSubtag.
+var dialects = map[string]func(content string) (Tag, error){
+	"json":         parseClassicSubtag,
+	"validate":     parseValidateSubtag,
+	"protobuf":     parseProtoSubtag,
+	"protobuf_key": parseProtoSubtag,
+	"protobuf_val": parseProtoSubtag,
+	"xml":          parseXMLSubtag,
+}
+
+// dialectFormatters write tags back as the dialects they were parsed by, for
+// tags whose content was not written by hand, e.g. merged ones.
+var dialectFormatters = map[string]func(tag *Tag) string{
+	"json":         formatClassicSubtag,
+	"validate":     formatValidateSubtag,
+	"protobuf":     formatProtoSubtag,
+	"protobuf_key": formatProtoSubtag,
+	"protobuf_val": formatProtoSubtag,
+	"xml":          formatClassicSubtag,
+}
+
+// hasPositionalValue reports whether tag is written in the classic style, with
+// a value apart from its options.
+func hasPositionalValue(tag *Tag) bool {
+	return tag.dialect && (tag.name == "json" || tag.name == "xml")
+}
+
+func parseDialectSubtag(name, content string, config *parseConfig) (Tag, error) {
+	if parse, exists := dialects[name]; exists && config.dialects {
+		tag, err := parse(content)
+		tag.name = name
+		tag.dialect = true
+		tag.normalize = config.normalize
+		tag.index()
+		if err == nil {
+			err = config.limits.checkParams(&tag)
+		}
+		return tag, err
+	} else if config.goEscapes {
+		tag, err := parseSubtag(escapeLiteralQuotes(content), true, config)
+		tag.content = content
+		return tag, err
+	} else {
+		return parseSubtag(content, true, config)
+	}
+}
+
+func formatDialectSubtag(tag *Tag) string {
+	if format, exists := dialectFormatters[tag.name]; exists && tag.dialect {
+		return format(tag)
+	} else {
+		return formatSubtag(tag)
+	}
+}
+
+// formatSubtag writes GORM style tags with the options first, in their order,
+// and the params after them, sorted by name.
+func formatSubtag(tag *Tag) string {
+	const (
+		itemsDelimiter = ";"
+		separator      = ":"
+	)
+
+	items := slices.Clone(tag.rawOptions)
+	for _, name := range sortedParamNames(tag) {
+		for _, param := range tag.GetParamAll(name) {
+			items = append(items, name+separator+param.Raw)
+		}
+	}
+	return strings.Join(items, itemsDelimiter)
+}
+
+// parseClassicSubtag parses tags of the standard library style, where the value
+// comes first and is followed by comma separated options.
+func parseClassicSubtag(content string) (Tag, error) {
+	const optionsDelimiter = ","
+
+	value, rest, found := strings.Cut(content, optionsDelimiter)
+
+	tag := Tag{
+		value:      value,
+		params:     make(map[string]TagParam),
+		options:    make([]string, 0),
+		rawOptions: make([]string, 0),
+		content:    content,
+	}
+
+	if found {
+		tag.options = strings.Split(rest, optionsDelimiter)
+		tag.rawOptions = tag.options
+	}
+
+	return tag, nil
+}
+
+// parseValidateSubtag keeps every rule of a validate tag as an option, the rule
+// tree itself is built and checked by ParseValidateTag.
+func parseValidateSubtag(content string) (Tag, error) {
+	tag := Tag{
+		params:     make(map[string]TagParam),
+		options:    make([]string, 0),
+		rawOptions: make([]string, 0),
+		content:    content,
+	}
+
+	if content != "" {
+		tag.options = strings.Split(content, validateRulesDelimiter)
+		tag.rawOptions = tag.options
+		tag.value = tag.options[0]
+	}
+
+	return tag, nil
+}
+
+func formatClassicSubtag(tag *Tag) string {
+	const optionsDelimiter = ","
+	return strings.Join(append([]string{tag.value}, tag.options...), optionsDelimiter)
+}
+
+func formatValidateSubtag(tag *Tag) string {
+	return strings.Join(tag.options, validateRulesDelimiter)
+}
+
+// formatProtoSubtag writes the flags first and the params after them, with
+// `def` last as it takes the rest of the tag.
+func formatProtoSubtag(tag *Tag) string {
+	const defaultParam = "def"
+
+	items := slices.Clone(tag.options)
+	for _, name := range sortedParamNames(tag) {
+		if name != defaultParam {
+			items = append(items, name+protoParamDelimiter+tag.params[name].Value)
+		}
+	}
+	if param, exists := tag.params[defaultParam]; exists {
+		items = append(items, defaultParam+protoParamDelimiter+param.Value)
+	}
+	return strings.Join(items, protoItemsDelimiter)
+}
+
+func sortedParamNames(tag *Tag) []string {
+	names := make([]string, 0, len(tag.params))
+	for name := range tag.params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,172 @@
+// Package env loads structs from environment variables described by env tags.
+// Tags are written in the GORM style: the variable name is the first option
+// other than `required` and `file`, next to the `default`, `separator`,
+// `pairSeparator` and `prefix` params:
+//
+//	type Config struct {
+//		Port     int           `env:"PORT;default:8080"`
+//		Hosts    []string      `env:"HOSTS;separator:' '"`
+//		Password string        `env:"PASSWORD_FILE;file;required"`
+//		Timeout  time.Duration `env:"TIMEOUT;default:5s"`
+//		Database Database      `env:"prefix:DB_"`
+//	}
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/kuzgoga/fogg"
+	"github.com/kuzgoga/fogg/internal/convert"
+)
+
+const (
+	tagName              = "env"
+	skipTag              = "-"
+	requiredOption       = "required"
+	fileOption           = "file"
+	defaultParam         = "default"
+	separatorParam       = "separator"
+	pairSeparatorParam   = "pairSeparator"
+	prefixParam          = "prefix"
+	defaultSeparator     = ","
+	defaultPairSeparator = ":"
+)
+
+// Load fills the struct cfg points to from the environment of the process.
+// Missing and invalid variables are all reported at once as Errors, other
+// errors mean the tags or field types are wrong.
+func Load(cfg any) error {
+	return load(cfg, os.LookupEnv)
+}
+
+// LoadMap fills the struct cfg points to from vars instead of the environment.
+func LoadMap(cfg any, vars map[string]string) error {
+	return load(cfg, func(name string) (string, bool) {
+		value, exists := vars[name]
+		return value, exists
+	})
+}
+
+type loader struct {
+	lookup func(name string) (string, bool)
+	errors Errors
+}
+
+func load(cfg any, lookup func(name string) (string, bool)) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf(notStructPointerErr, reflect.TypeOf(cfg)))
+	}
+
+	loader := loader{lookup: lookup}
+	if err := loader.loadStruct(value.Elem(), "", ""); err != nil {
+		return err
+	}
+	if len(loader.errors) != 0 {
+		return loader.errors
+	}
+	return nil
+}
+
+func (loader *loader) loadStruct(value reflect.Value, prefix string, path string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		content, tagged := field.Tag.Lookup(tagName)
+		if !field.IsExported() || content == skipTag {
+			continue
+		}
+
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		tag, err := fogg.ParseSubtag(content, true)
+		if err != nil {
+			return errors.New(fmt.Sprintf(invalidTagErr, fieldPath, err))
+		}
+
+		name := variableName(&tag)
+
+		fieldValue := value.Field(i)
+		if name == "" && isNested(field.Type) {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if err := loader.loadStruct(fieldValue, prefix+tag.GetParamOr(prefixParam, ""), fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !tagged {
+			continue
+		}
+		if name == "" {
+			return errors.New(fmt.Sprintf(noVariableNameErr, fieldPath))
+		}
+		if !convert.Supported(field.Type) {
+			return errors.New(fmt.Sprintf(unsupportedTypeErr, fieldPath, field.Type))
+		}
+
+		loader.loadField(fieldValue, &tag, prefix+name, fieldPath)
+	}
+	return nil
+}
+
+func (loader *loader) loadField(value reflect.Value, tag *fogg.Tag, name string, path string) {
+	text, exists := loader.lookup(name)
+	if !exists {
+		if param := tag.GetParam(defaultParam); param != nil {
+			text, exists = param.Value, true
+		}
+	}
+
+	if !exists {
+		if tag.HasOption(requiredOption) {
+			loader.errors = append(loader.errors, &VarError{Variable: name, Field: path})
+		}
+		return
+	}
+
+	if tag.HasOption(fileOption) {
+		content, err := os.ReadFile(text)
+		if err != nil {
+			loader.errors = append(loader.errors, &VarError{Variable: name, Field: path, Err: err})
+			return
+		}
+		text = string(content)
+	}
+
+	separator := tag.GetParamOr(separatorParam, defaultSeparator)
+	pairSeparator := tag.GetParamOr(pairSeparatorParam, defaultPairSeparator)
+	if err := convert.Set(value, text, separator, pairSeparator); err != nil {
+		loader.errors = append(loader.errors, &VarError{Variable: name, Field: path, Err: err})
+	}
+}
+
+// variableName returns the first option of the tag which is neither `required`
+// nor `file`, so the name may follow them.
+func variableName(tag *fogg.Tag) string {
+	for _, option := range tag.GetRawOptions() {
+		if option != requiredOption && option != fileOption {
+			return option
+		}
+	}
+	return ""
+}
+
+// isNested reports whether fields of the type are loaded from variables of
+// their own, which is the case for structs parsing no text.
+func isNested(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Struct && !convert.Supported(fieldType)
+}
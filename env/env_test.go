@@ -0,0 +1,178 @@
+package env
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type database struct {
+	Host string `env:"HOST;default:localhost"`
+	Port int    `env:"PORT;required"`
+}
+
+type config struct {
+	Port     int               `env:"PORT;default:8080"`
+	Debug    bool              `env:"DEBUG"`
+	Hosts    []string          `env:"HOSTS;separator:' '"`
+	Weights  map[string]int    `env:"WEIGHTS"`
+	Ratios   []float64         `env:"RATIOS;separator:';'"`
+	Timeout  time.Duration     `env:"TIMEOUT;default:5s"`
+	Address  netip.Addr        `env:"ADDRESS;default:127.0.0.1"`
+	Limit    *uint16           `env:"LIMIT"`
+	Database database          `env:"prefix:DB_"`
+	Replica  *database         `env:"prefix:REPLICA_"`
+	Cache    struct{ TTL int } ``
+	Ignored  string            `env:"-"`
+	Labels   map[string]string `env:"LABELS;pairSeparator:="`
+	internal string
+}
+
+func TestLoadMap(t *testing.T) {
+	var cfg config
+	err := LoadMap(&cfg, map[string]string{
+		"DEBUG":        "true",
+		"HOSTS":        "a b",
+		"WEIGHTS":      "a:1,b:2",
+		"RATIOS":       "0.5;1.5",
+		"LIMIT":        "0x10",
+		"DB_PORT":      "5432",
+		"REPLICA_HOST": "replica",
+		"REPLICA_PORT": "5433",
+		"LABELS":       "team=core,tier=1",
+		"Ignored":      "x",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	limit := uint16(16)
+	expected := config{
+		Port:     8080,
+		Debug:    true,
+		Hosts:    []string{"a", "b"},
+		Weights:  map[string]int{"a": 1, "b": 2},
+		Ratios:   []float64{0.5, 1.5},
+		Timeout:  5 * time.Second,
+		Address:  netip.MustParseAddr("127.0.0.1"),
+		Limit:    &limit,
+		Database: database{Host: "localhost", Port: 5432},
+		Replica:  &database{Host: "replica", Port: 5433},
+		Labels:   map[string]string{"team": "core", "tier": "1"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("got %+v, expected %+v", cfg, expected)
+	}
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	var cfg config
+	err := LoadMap(&cfg, map[string]string{
+		"PORT":         "http",
+		"TIMEOUT":      "5 parsecs",
+		"ADDRESS":      "localhost",
+		"WEIGHTS":      "a:x",
+		"DB_PORT":      "1",
+		"REPLICA_HOST": "replica",
+	})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+
+	expected := []struct {
+		variable string
+		field    string
+		missing  bool
+	}{
+		{"PORT", "Port", false},
+		{"WEIGHTS", "Weights", false},
+		{"TIMEOUT", "Timeout", false},
+		{"ADDRESS", "Address", false},
+		{"REPLICA_PORT", "Replica.Port", true},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("got errors %v", errs)
+	}
+	for i, test := range expected {
+		if errs[i].Variable != test.variable || errs[i].Field != test.field || (errs[i].Err == nil) != test.missing {
+			t.Errorf("error %d: got %v", i, errs[i])
+		}
+	}
+
+	if message := errs[4].Error(); message != `required variable REPLICA_PORT of field Replica.Port is not set` {
+		t.Errorf("unexpected message %s", message)
+	}
+	if message := errs[0].Error(); message != `invalid value of variable PORT for field Port: strconv.ParseInt: parsing "http": invalid syntax` {
+		t.Errorf("unexpected message %s", message)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Password string `env:"PASSWORD_FILE;file;required"`
+		Token    string `env:"TOKEN_FILE;file"`
+		Key      string `env:"file;KEY_FILE"`
+		Port     int    `env:"required;PORT"`
+	}
+	err := LoadMap(&cfg, map[string]string{
+		"PASSWORD_FILE": path,
+		"TOKEN_FILE":    filepath.Join(t.TempDir(), "missing"),
+		"KEY_FILE":      path,
+		"PORT":          "8080",
+	})
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Variable != "TOKEN_FILE" || !errors.Is(errs[0], os.ErrNotExist) {
+		t.Errorf("expected missing token file error, got %v", err)
+	}
+	if cfg.Password != "secret" || cfg.Key != "secret" || cfg.Port != 8080 {
+		t.Errorf("expected password and key to be read from file, got %+v", cfg)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("FOGG_ENV_TEST_NAME", "fogg")
+
+	var cfg struct {
+		Name string `env:"FOGG_ENV_TEST_NAME"`
+	}
+	if err := Load(&cfg); err != nil || cfg.Name != "fogg" {
+		t.Errorf("got %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		cfg any
+		err string
+	}{
+		{config{}, `expected a pointer to a struct, got env.config`},
+		{(*config)(nil), `expected a pointer to a struct, got *env.config`},
+		{&struct {
+			A int `env:"A;default:'1"`
+		}{}, `invalid env tag of field A: unclosed backtick in tag`},
+		{&struct {
+			A int `env:"required;default:1"`
+		}{}, `env tag of field A has no variable name`},
+		{&struct {
+			A chan int `env:"A"`
+		}{}, `field A has unsupported type chan int`},
+	}
+
+	for _, test := range tests {
+		if err := LoadMap(test.cfg, nil); err == nil || err.Error() != test.err {
+			t.Errorf("got error `%v`, expected `%s`", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	notStructPointerErr string = `expected a pointer to a struct, got %s`
+	invalidTagErr       string = `invalid env tag of field %s: %s`
+	noVariableNameErr   string = `env tag of field %s has no variable name`
+	unsupportedTypeErr  string = `field %s has unsupported type %s`
+	missingVariableErr  string = `required variable %s of field %s is not set`
+	invalidVariableErr  string = `invalid value of variable %s for field %s: %s`
+)
+
+// VarError reports a variable that is missing or cannot be parsed into its
+// field. Err is nil for missing variables.
+type VarError struct {
+	Variable string
+	// Field locates the field from the loaded struct, e.g. `Database.Port`.
+	Field string
+	Err   error
+}
+
+func (err *VarError) Error() string {
+	if err.Err == nil {
+		return fmt.Sprintf(missingVariableErr, err.Variable, err.Field)
+	}
+	return fmt.Sprintf(invalidVariableErr, err.Variable, err.Field, err.Err)
+}
+
+func (err *VarError) Unwrap() error {
+	return err.Err
+}
+
+// Errors holds every missing and invalid variable of a loaded struct.
+type Errors []*VarError
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
@@ -1,10 +1,49 @@
 package fogg
 
 const (
-	duplicatedTagsErr   string = `duplicated tags with name "%s"`
-	emptyNameTagErr     string = `invalid param with empty name and value "%s"`
-	duplicatedParamErr  string = `duplicated param "%s" in tag`
-	unclosedBacktickErr string = `unclosed backtick in tag`
-	valueLessTagErr     string = "Invalid `%s` tag syntax"
-	nonQuotedValueErr   string = "`%s` tag value must be in quotation marks"
+	duplicatedTagsErr      string = `duplicated tags with name "%s"`
+	emptyNameTagErr        string = `invalid param with empty name and value "%s"`
+	duplicatedParamErr     string = `duplicated param "%s" in tag`
+	unclosedBacktickErr    string = `unclosed backtick in tag`
+	valueLessTagErr        string = "Invalid `%s` tag syntax"
+	nonQuotedValueErr      string = "`%s` tag value must be in quotation marks"
+	emptyValidateRuleErr   string = `empty rule at item %d of validate tag`
+	keysWithoutDiveErr     string = `"keys" at item %d of validate tag must follow "dive"`
+	endkeysWithoutKeysErr  string = `"endkeys" at item %d of validate tag has no matching "keys"`
+	unclosedKeysErr        string = `"keys" at item %d of validate tag is not closed by "endkeys"`
+	protoTooShortErr       string = `protobuf tag "%s" must start with a wire type and a field number`
+	protoWireTypeErr       string = `unknown protobuf wire type "%s"`
+	protoFieldNumberErr    string = `invalid protobuf field number "%s"`
+	xmlInvalidTagErr       string = `invalid xml tag "%s"`
+	xmlNamespaceErr        string = `namespace without name in xml tag "%s"`
+	xmlTrailingParentErr   string = `trailing ">" in xml tag "%s"`
+	xmlParentsErr          string = `parents chain of xml tag "%s" is only valid for elements`
+	xmlNameConflictErr     string = `name "%s" in xml tag of field %s conflicts with name "%s" in %s.XMLName`
+	mergeValueConflictErr  string = `conflicting values "%s" and "%s" of tag "%s"`
+	invalidTagNameErr      string = `invalid tag name "%s"`
+	invalidTagContentErr   string = `content of "%s" tag must not close its quotes`
+	retagUnexportedErr     string = `cannot retag %s, its field %s is unexported`
+	retagStructOfErr       string = `cannot retag %s: %v`
+	leadingSpaceWarn       string = `whitespace before the first tag`
+	trailingSpaceWarn      string = `whitespace after the last tag`
+	separatorWarn          string = `tags separated by %q instead of a single space`
+	missingSeparatorWarn   string = `no space before the "%s" tag`
+	unquotedValueWarn      string = "`%s` tag value is not in quotation marks"
+	unparsedContentWarn    string = "`%s` tag content is kept unparsed: %s"
+	strictPairErr          string = `bad syntax for struct tag pair at offset %d`
+	strictKeyErr           string = `bad syntax for struct tag key at offset %d`
+	strictValueErr         string = `bad syntax for struct tag value at offset %d`
+	strictSpaceErr         string = `key:"value" pairs not separated by spaces at offset %d`
+	strictValueSpaceErr    string = `suspicious space in struct tag value at offset %d`
+	goEscapeErr            string = "invalid escape `%s` at offset %d of `%s` tag value"
+	goNewlineErr           string = "newline at offset %d of `%s` tag value"
+	limitExceededErr       string = `%s limit of %d exceeded`
+	notStructTypeErr       string = `expected a struct type, got %s`
+	fieldTagErr            string = `invalid tag of field %s: %s`
+	overlaySyntaxErr       string = `line %d of overlay must hold a field and its tags`
+	overlayLineErr         string = `line %d of overlay: %s`
+	overlayKeyErr          string = `invalid overlay key "%s", expected pkg.Type.Field`
+	overlayTagsErr         string = `invalid tags in overlay "%s": %s`
+	overlayDuplicatedErr   string = `duplicated overlay for "%s"`
+	overlayMissingFieldErr string = `overlay "%s" targets a field which %s does not have`
 )
@@ -0,0 +1,10 @@
+package flagbind
+
+const (
+	notStructPointerErr string = `expected a pointer to a struct, got %s`
+	invalidTagErr       string = `invalid flag tag of field %s: %s`
+	unsupportedTypeErr  string = `field %s has unsupported type %s`
+	redefinedFlagErr    string = `flag -%s of field %s is already defined`
+	invalidDefaultErr   string = `invalid default of flag -%s: %s`
+	missingFlagsErr     string = `missing required flags: %s`
+)
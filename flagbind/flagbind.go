@@ -0,0 +1,340 @@
+// Package flagbind registers the fields of a struct as command-line flags
+// described by flag tags. Tags are written in the GORM style with the `name`,
+// `short`, `usage`, `default`, `separator`, `group` and `prefix` params and the
+// `required` option:
+//
+//	type Options struct {
+//		Listen  string        `flag:"name:listen;short:l;usage:'address to listen on';default:':8080'"`
+//		Timeout time.Duration `flag:"usage:'request timeout';required"`
+//		Tags    []string      `flag:"tag;usage:'tags, repeatable';separator:','"`
+//		DB      Database      `flag:"prefix:db-;group:Database"`
+//	}
+package flagbind
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kuzgoga/fogg"
+	"github.com/kuzgoga/fogg/internal/convert"
+)
+
+const (
+	tagName            = "flag"
+	skipTag            = "-"
+	requiredOption     = "required"
+	nameParam          = "name"
+	shortParam         = "short"
+	usageParam         = "usage"
+	defaultParam       = "default"
+	separatorParam     = "separator"
+	groupParam         = "group"
+	prefixParam        = "prefix"
+	defaultSeparator   = ","
+	pairSeparator      = ":"
+	usageIndent        = "  "
+	usageMessageIndent = "    \t"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fieldValue is the flag.Value of a bound field. It keeps what Bind read from
+// the tag so CheckRequired and PrintHelp can find it in the FlagSet.
+type fieldValue struct {
+	value     reflect.Value
+	name      string
+	short     string
+	group     string
+	separator string
+	required  bool
+	set       bool
+}
+
+func (field *fieldValue) String() string {
+	if field == nil || !field.value.IsValid() {
+		return ""
+	}
+
+	if field.value.Kind() == reflect.Slice {
+		items := make([]string, field.value.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(field.value.Index(i).Interface())
+		}
+		return strings.Join(items, field.separator)
+	}
+	if field.value.Kind() == reflect.Pointer && field.value.IsNil() {
+		return ""
+	}
+	return fmt.Sprint(reflect.Indirect(field.value).Interface())
+}
+
+// Set parses text into the field. Slices collect the items of every occurrence
+// of the flag, replacing their default on the first one.
+func (field *fieldValue) Set(text string) error {
+	if field.value.Kind() != reflect.Slice {
+		if err := convert.Set(field.value, text, field.separator, pairSeparator); err != nil {
+			return err
+		}
+		field.set = true
+		return nil
+	}
+
+	items := reflect.New(field.value.Type()).Elem()
+	if err := convert.Set(items, text, field.separator, pairSeparator); err != nil {
+		return err
+	}
+	if !field.set {
+		field.value.Set(reflect.MakeSlice(field.value.Type(), 0, items.Len()))
+	}
+	field.set = true
+	field.value.Set(reflect.AppendSlice(field.value, items))
+	return nil
+}
+
+func (field *fieldValue) IsBoolFlag() bool {
+	fieldType := field.value.Type()
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Bool
+}
+
+// Bind registers a flag in fs for every supported field of the struct opts
+// points to, nested structs included, and sets fs.Usage to print grouped help.
+func Bind(fs *flag.FlagSet, opts any) error {
+	value := reflect.ValueOf(opts)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf(notStructPointerErr, reflect.TypeOf(opts)))
+	}
+
+	if err := bindStruct(fs, value.Elem(), "", "", ""); err != nil {
+		return err
+	}
+
+	fs.Usage = func() {
+		if fs.Name() != "" {
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage:\n")
+		}
+		PrintHelp(fs, fs.Output())
+	}
+	return nil
+}
+
+func bindStruct(fs *flag.FlagSet, value reflect.Value, prefix string, group string, path string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		content, tagged := field.Tag.Lookup(tagName)
+		if !field.IsExported() || content == skipTag {
+			continue
+		}
+
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		tag, err := fogg.ParseSubtag(content, true)
+		if err != nil {
+			return errors.New(fmt.Sprintf(invalidTagErr, fieldPath, err))
+		}
+
+		fieldValue := value.Field(i)
+		fieldGroup := tag.GetParamOr(groupParam, group)
+		if isNested(field.Type) {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if err := bindStruct(fs, fieldValue, prefix+tag.GetParamOr(prefixParam, ""), fieldGroup, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !tagged {
+			continue
+		}
+		if !convert.Supported(field.Type) {
+			return errors.New(fmt.Sprintf(unsupportedTypeErr, fieldPath, field.Type))
+		}
+		if err := bindField(fs, fieldValue, &tag, prefix, fieldGroup, field.Name, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindField(fs *flag.FlagSet, value reflect.Value, tag *fogg.Tag, prefix, group, fieldName, path string) error {
+	name := tag.GetValue()
+	if name == requiredOption {
+		name = ""
+	}
+	name = tag.GetParamOr(nameParam, name)
+	if name == "" {
+		name = strings.ToLower(fieldName)
+	}
+
+	binding := &fieldValue{
+		value:     value,
+		name:      prefix + name,
+		short:     tag.GetParamOr(shortParam, ""),
+		group:     group,
+		separator: tag.GetParamOr(separatorParam, defaultSeparator),
+		required:  tag.HasOption(requiredOption),
+	}
+
+	if param := tag.GetParam(defaultParam); param != nil {
+		if err := convert.Set(value, param.Value, binding.separator, pairSeparator); err != nil {
+			return errors.New(fmt.Sprintf(invalidDefaultErr, binding.name, err))
+		}
+	}
+
+	usage := tag.GetParamOr(usageParam, "")
+	for _, flagName := range []string{binding.name, binding.short} {
+		if flagName == "" {
+			continue
+		}
+		if fs.Lookup(flagName) != nil {
+			return errors.New(fmt.Sprintf(redefinedFlagErr, flagName, path))
+		}
+		fs.Var(binding, flagName, usage)
+	}
+	return nil
+}
+
+// isNested reports whether the fields of the type are bound as flags of their
+// own, which is the case for structs parsing no text.
+func isNested(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Struct && !convert.Supported(fieldType)
+}
+
+// Parse parses args with fs and checks that required flags are set.
+func Parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return CheckRequired(fs)
+}
+
+// CheckRequired reports all required flags bound in fs which are not set.
+func CheckRequired(fs *flag.FlagSet) error {
+	missing := []string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if binding, ok := f.Value.(*fieldValue); ok && f.Name == binding.name && binding.required && !binding.set {
+			missing = append(missing, "-"+binding.name)
+		}
+	})
+
+	if len(missing) != 0 {
+		return errors.New(fmt.Sprintf(missingFlagsErr, strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// PrintHelp writes the flags of fs to w. Flags without a group come first,
+// then every group under its name, in alphabetical order. Short names are
+// listed next to the long ones.
+func PrintHelp(fs *flag.FlagSet, w io.Writer) {
+	groups := []string{""}
+	flags := map[string][]*flag.Flag{}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		group := ""
+		if binding, ok := f.Value.(*fieldValue); ok {
+			if f.Name != binding.name {
+				return
+			}
+			group = binding.group
+		}
+		if _, exists := flags[group]; !exists && group != "" {
+			groups = append(groups, group)
+		}
+		flags[group] = append(flags[group], f)
+	})
+
+	sort.Strings(groups[1:])
+
+	printed := false
+	for _, group := range groups {
+		if len(flags[group]) == 0 {
+			continue
+		}
+		if group != "" {
+			if printed {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s:\n", group)
+		}
+		printed = true
+		for _, f := range flags[group] {
+			printFlag(w, f)
+		}
+	}
+}
+
+func printFlag(w io.Writer, f *flag.Flag) {
+	names := "-" + f.Name
+	binding, bound := f.Value.(*fieldValue)
+	if bound && binding.short != "" {
+		names = "-" + binding.short + ", " + names
+	}
+
+	typeName, usage := flag.UnquoteUsage(f)
+	if bound && !strings.Contains(f.Usage, "`") {
+		typeName = valueTypeName(binding.value.Type())
+	}
+
+	line := usageIndent + names
+	if typeName != "" {
+		line += " " + typeName
+	}
+	line += "\n" + usageMessageIndent + strings.ReplaceAll(usage, "\n", "\n"+usageMessageIndent)
+
+	if bound && binding.required {
+		line += " (required)"
+	} else if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+		if bound && binding.value.Kind() != reflect.String && binding.value.Kind() != reflect.Slice {
+			line += fmt.Sprintf(" (default %v)", f.DefValue)
+		} else {
+			line += fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+	}
+	fmt.Fprintln(w, line)
+}
+
+func valueTypeName(valueType reflect.Type) string {
+	if valueType == durationType {
+		return "duration"
+	}
+
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return ""
+	case reflect.Pointer:
+		return valueTypeName(valueType.Elem())
+	case reflect.Slice:
+		return valueTypeName(valueType.Elem()) + "..."
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return "value"
+}
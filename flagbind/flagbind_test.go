@@ -0,0 +1,177 @@
+package flagbind
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type database struct {
+	Host string `flag:"host;usage:'database host';default:localhost"`
+	Port int    `flag:"port;usage:'database port';required"`
+}
+
+type options struct {
+	Listen  string        `flag:"name:listen;short:l;usage:'address to listen on; IPv4 or IPv6';default:':8080'"`
+	Verbose bool          `flag:"short:v;usage:'verbose output'"`
+	Workers int           `flag:"workers;usage:'number of workers';default:4"`
+	Timeout time.Duration `flag:"timeout;usage:'request timeout';required"`
+	Tags    []string      `flag:"tag;usage:'tags, repeatable';default:'a,b'"`
+	Ratio   float64       "flag:\"ratio;usage:'sampling `ratio`';group:Tuning\""
+	DB      database      `flag:"prefix:db-;group:Database"`
+	Ignored string        `flag:"-"`
+	Plain   string
+}
+
+func newFlagSet(t *testing.T) (*flag.FlagSet, *options) {
+	t.Helper()
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	opts := &options{}
+	if err := Bind(fs, opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return fs, opts
+}
+
+func TestBind(t *testing.T) {
+	fs, opts := newFlagSet(t)
+
+	err := Parse(fs, []string{"-l", "127.0.0.1:80", "-v", "-timeout", "3s", "-tag", "x,y", "-tag", "z", "-ratio", "0.5", "-db-port", "5432", "rest"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := options{
+		Listen:  "127.0.0.1:80",
+		Verbose: true,
+		Workers: 4,
+		Timeout: 3 * time.Second,
+		Tags:    []string{"x", "y", "z"},
+		Ratio:   0.5,
+		DB:      database{Host: "localhost", Port: 5432},
+	}
+	if !reflect.DeepEqual(*opts, expected) {
+		t.Errorf("got %+v, expected %+v", *opts, expected)
+	}
+	if !reflect.DeepEqual(fs.Args(), []string{"rest"}) {
+		t.Errorf("unexpected args %v", fs.Args())
+	}
+}
+
+func TestBindDefaults(t *testing.T) {
+	fs, opts := newFlagSet(t)
+
+	err := Parse(fs, []string{"-verbose=false"})
+	if err == nil || err.Error() != `missing required flags: -db-port, -timeout` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if opts.Listen != ":8080" || opts.Workers != 4 || !reflect.DeepEqual(opts.Tags, []string{"a", "b"}) {
+		t.Errorf("expected defaults, got %+v", *opts)
+	}
+
+	if err := Parse(fs, []string{"-workers", "many"}); err == nil {
+		t.Errorf("expected invalid value error")
+	}
+}
+
+func TestBindPointersAndFailedSet(t *testing.T) {
+	var opts struct {
+		Debug *bool `flag:"debug;short:d"`
+		Port  int   `flag:"port;required"`
+	}
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := Bind(fs, &opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := fs.Parse([]string{"-d", "rest"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if opts.Debug == nil || !*opts.Debug || !reflect.DeepEqual(fs.Args(), []string{"rest"}) {
+		t.Errorf("expected -d to set Debug without a value, got %v, %v", opts.Debug, fs.Args())
+	}
+
+	if err := fs.Lookup("port").Value.Set("many"); err == nil {
+		t.Errorf("expected invalid value error")
+	}
+	if err := CheckRequired(fs); err == nil || err.Error() != `missing required flags: -port` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPrintHelp(t *testing.T) {
+	fs, _ := newFlagSet(t)
+	fs.String("config", "", "config `file`")
+
+	var help bytes.Buffer
+	PrintHelp(fs, &help)
+
+	const expected = `  -config file
+    	config file
+  -l, -listen string
+    	address to listen on; IPv4 or IPv6 (default ":8080")
+  -tag string...
+    	tags, repeatable (default "a,b")
+  -timeout duration
+    	request timeout (required)
+  -v, -verbose
+    	verbose output
+  -workers int
+    	number of workers (default 4)
+
+Database:
+  -db-host string
+    	database host (default "localhost")
+  -db-port int
+    	database port (required)
+
+Tuning:
+  -ratio ratio
+    	sampling ratio
+`
+	if help.String() != expected {
+		t.Errorf("unexpected help:\n%s\nexpected:\n%s", help.String(), expected)
+	}
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.Usage()
+	if usage.String() != "Usage of server:\n"+expected {
+		t.Errorf("unexpected usage:\n%s", usage.String())
+	}
+}
+
+func TestBindInvalid(t *testing.T) {
+	tests := []struct {
+		opts any
+		err  string
+	}{
+		{options{}, `expected a pointer to a struct, got flagbind.options`},
+		{&struct {
+			A int `flag:"usage:'unclosed"`
+		}{}, `invalid flag tag of field A: unclosed backtick in tag`},
+		{&struct {
+			A chan int `flag:"a"`
+		}{}, `field A has unsupported type chan int`},
+		{&struct {
+			A int `flag:"a;default:x"`
+		}{}, `invalid default of flag -a: strconv.ParseInt: parsing "x": invalid syntax`},
+		{&struct {
+			A int `flag:"a"`
+			B int `flag:"b;short:a"`
+		}{}, `flag -a of field B is already defined`},
+	}
+
+	for _, test := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := Bind(fs, test.opts); err == nil || err.Error() != test.err {
+			t.Errorf("got error `%v`, expected `%s`", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package convert sets reflected values from their text form, as read from
+// environment variables, flags or CSV cells.
+package convert
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const unsupportedTypeErr string = `unsupported type %s`
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Supported reports whether Set can set values of the type.
+func Supported(valueType reflect.Type) bool {
+	if reflect.PointerTo(valueType).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch valueType.Kind() {
+	case reflect.Pointer:
+		return Supported(valueType.Elem())
+	case reflect.Slice:
+		return Supported(valueType.Elem())
+	case reflect.Map:
+		return Supported(valueType.Key()) && Supported(valueType.Elem())
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Set parses text into value, which must be settable. Slice items are split by
+// separator and map entries are split by separator, then by pairSeparator.
+// Types implementing encoding.TextUnmarshaler parse the text themselves.
+func Set(value reflect.Value, text string, separator string, pairSeparator string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		if err := Set(elem.Elem(), text, separator, pairSeparator); err != nil {
+			return err
+		}
+		value.Set(elem)
+	case reflect.Slice:
+		items := strings.Split(text, separator)
+		if text == "" {
+			items = nil
+		}
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := Set(slice.Index(i), item, separator, pairSeparator); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Map:
+		entries := reflect.MakeMap(value.Type())
+		if text != "" {
+			for _, entry := range strings.Split(text, separator) {
+				key, item, _ := strings.Cut(entry, pairSeparator)
+				mapKey := reflect.New(value.Type().Key()).Elem()
+				if err := Set(mapKey, key, separator, pairSeparator); err != nil {
+					return err
+				}
+				mapItem := reflect.New(value.Type().Elem()).Elem()
+				if err := Set(mapItem, item, separator, pairSeparator); err != nil {
+					return err
+				}
+				entries.SetMapIndex(mapKey, mapItem)
+			}
+		}
+		value.Set(entries)
+	default:
+		return setScalar(value, text)
+	}
+	return nil
+}
+
+func setScalar(value reflect.Value, text string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			parsed, err := time.ParseDuration(text)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(parsed))
+			return nil
+		}
+		parsed, err := strconv.ParseInt(text, 0, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 0, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	default:
+		return errors.New(fmt.Sprintf(unsupportedTypeErr, value.Type()))
+	}
+	return nil
+}
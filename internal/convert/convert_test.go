@@ -0,0 +1,85 @@
+package convert
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	five := 5
+	tests := []struct {
+		text     string
+		expected any
+	}{
+		{"text", "text"},
+		{"true", true},
+		{"-0x1f", int64(-31)},
+		{"255", uint8(255)},
+		{"1.5", float32(1.5)},
+		{"1m30s", 90 * time.Second},
+		{"5", &five},
+		{"a,b", []string{"a", "b"}},
+		{"", []int{}},
+		{"1,2", []*int{intPointer(1), intPointer(2)}},
+		{"a:1,b:2", map[string]int{"a": 1, "b": 2}},
+		{"", map[string]int{}},
+		{"::1", netip.MustParseAddr("::1")},
+		{"10.0.0.1,10.0.0.2", []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")}},
+	}
+
+	for _, test := range tests {
+		value := reflect.New(reflect.TypeOf(test.expected)).Elem()
+		if err := Set(value, test.text, ",", ":"); err != nil {
+			t.Errorf("Set(%q) into %T: unexpected error: %s", test.text, test.expected, err)
+		} else if !reflect.DeepEqual(value.Interface(), test.expected) {
+			t.Errorf("Set(%q) = %#v; want %#v", test.text, value.Interface(), test.expected)
+		}
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		text  string
+		value any
+		err   string
+	}{
+		{"yes", false, `strconv.ParseBool: parsing "yes": invalid syntax`},
+		{"256", uint8(0), `strconv.ParseUint: parsing "256": value out of range`},
+		{"1", struct{}{}, `unsupported type struct {}`},
+		{"a:x", map[string]int{}, `strconv.ParseInt: parsing "x": invalid syntax`},
+	}
+
+	for _, test := range tests {
+		value := reflect.New(reflect.TypeOf(test.value)).Elem()
+		if err := Set(value, test.text, ",", ":"); err == nil || err.Error() != test.err {
+			t.Errorf("Set(%q) into %T: got error `%v`, expected `%s`", test.text, test.value, err, test.err)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected bool
+	}{
+		{"", true},
+		{time.Duration(0), true},
+		{time.Time{}, true},
+		{[]map[string]*int{}, true},
+		{struct{}{}, false},
+		{map[string]chan int{}, false},
+		{func() {}, false},
+	}
+
+	for _, test := range tests {
+		if supported := Supported(reflect.TypeOf(test.value)); supported != test.expected {
+			t.Errorf("Supported(%T) = %v; want %v", test.value, supported, test.expected)
+		}
+	}
+}
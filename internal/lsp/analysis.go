@@ -0,0 +1,451 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/kuzgoga/fogg"
+)
+
+const (
+	codeSyntax         = "syntax"
+	codeDuplicateTag   = "duplicate-tag"
+	codeDuplicateParam = "duplicate-param"
+	codeEmptyName      = "empty-name"
+	codeUnknownKey     = "unknown-key"
+	codeKeySpelling    = "key-spelling"
+	codeMissingValue   = "missing-value"
+)
+
+// tagLiteral is the content of a raw string struct tag. Offset is the byte
+// offset of the content, just after the opening backtick, in the document.
+type tagLiteral struct {
+	offset  int
+	content string
+}
+
+func (tag tagLiteral) contains(offset int) bool {
+	return offset >= tag.offset && offset <= tag.offset+len(tag.content)
+}
+
+// fix replaces the whole content of a tag literal.
+type fix struct {
+	title   string
+	content string
+}
+
+// finding is a problem in a tag literal, start and end are offsets in the tag
+// content.
+type finding struct {
+	start    int
+	end      int
+	severity int
+	code     string
+	message  string
+	fix      *fix
+}
+
+type documentFinding struct {
+	diagnostic Diagnostic
+	tag        tagLiteral
+	fix        *fix
+}
+
+func findTags(text string) []tagLiteral {
+	fileSet := token.NewFileSet()
+	file, _ := parser.ParseFile(fileSet, "", text, parser.AllErrors|parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+
+	var tags []tagLiteral
+	ast.Inspect(file, func(node ast.Node) bool {
+		field, ok := node.(*ast.Field)
+		if !ok || field.Tag == nil || field.Tag.Kind != token.STRING {
+			return true
+		}
+
+		value := field.Tag.Value
+		if len(value) >= 2 && value[0] == '`' && value[len(value)-1] == '`' {
+			tags = append(tags, tagLiteral{
+				offset:  fileSet.Position(field.Tag.Pos()).Offset + 1,
+				content: value[1 : len(value)-1],
+			})
+		}
+		return true
+	})
+	return tags
+}
+
+func analyze(text string, tags []tagLiteral) []documentFinding {
+	var findings []documentFinding
+	for _, tag := range tags {
+		for _, finding := range analyzeTag(tag) {
+			findings = append(findings, documentFinding{
+				diagnostic: Diagnostic{
+					Range:    rangeOf(text, tag.offset+finding.start, tag.offset+finding.end),
+					Severity: finding.severity,
+					Code:     finding.code,
+					Source:   "fogg",
+					Message:  finding.message,
+				},
+				tag: tag,
+				fix: finding.fix,
+			})
+		}
+	}
+	return findings
+}
+
+func analyzeTag(tag tagLiteral) []finding {
+	file, err := fogg.ParseFile(tag.content)
+	if err != nil {
+		return []finding{syntaxFinding(tag.content, err)}
+	}
+
+	var findings []finding
+	seenTags := make(map[string]bool)
+	for tagIndex, node := range file.Tags {
+		if seenTags[node.Name] {
+			findings = append(findings, finding{
+				start:    node.Start,
+				end:      node.End,
+				severity: severityError,
+				code:     codeDuplicateTag,
+				message:  fmt.Sprintf("duplicated tags with name %q", node.Name),
+				fix: editFile(tag.content, "Remove duplicated tag", func(file *fogg.File) {
+					file.Tags = slices.Delete(file.Tags, tagIndex, tagIndex+1)
+				}),
+			})
+		}
+		seenTags[node.Name] = true
+
+		findings = append(findings, analyzeItems(tag.content, tagIndex, node)...)
+	}
+
+	if len(findings) == 0 {
+		if _, err := fogg.Parse(tag.content); err != nil {
+			findings = append(findings, finding{
+				start:    0,
+				end:      len(tag.content),
+				severity: severityError,
+				code:     codeSyntax,
+				message:  err.Error(),
+			})
+		}
+	}
+	return findings
+}
+
+func analyzeItems(content string, tagIndex int, node *fogg.TagNode) []finding {
+	var findings []finding
+	seenParams := make(map[string]bool)
+
+	for itemIndex, item := range node.Items {
+		if item.Empty() {
+			continue
+		}
+
+		name := item.Name()
+		if param := item.Param; param != nil && name == "" {
+			findings = append(findings, finding{
+				start:    param.Start,
+				end:      param.End,
+				severity: severityError,
+				code:     codeEmptyName,
+				message:  fmt.Sprintf("invalid param with empty name and value %q", param.Value()),
+			})
+			continue
+		} else if param != nil && seenParams[name] {
+			findings = append(findings, finding{
+				start:    param.Start,
+				end:      param.Start + len(param.Key),
+				severity: severityError,
+				code:     codeDuplicateParam,
+				message:  fmt.Sprintf("duplicated param %q in tag", name),
+				fix: editFile(content, "Remove duplicated param", func(file *fogg.File) {
+					tag := file.Tags[tagIndex]
+					tag.Items = slices.Delete(tag.Items, itemIndex, itemIndex+1)
+				}),
+			})
+		} else if param != nil {
+			seenParams[name] = true
+		}
+
+		if node.Name == "gorm" {
+			findings = append(findings, analyzeGormItem(content, tagIndex, itemIndex, item)...)
+		}
+	}
+	return findings
+}
+
+func analyzeGormItem(content string, tagIndex, itemIndex int, item *fogg.ItemNode) []finding {
+	name := item.Name()
+	var start, end int
+	if item.Param != nil {
+		start, end = item.Param.Start, item.Param.Start+len(item.Param.Key)
+	} else {
+		start, end = item.Option.Start, item.Option.End
+	}
+
+	rename := func(replacement string) *fix {
+		return editFile(content, fmt.Sprintf("Replace with %q", replacement), func(file *fogg.File) {
+			renamed := file.Tags[tagIndex].Items[itemIndex]
+			if renamed.Param != nil {
+				renamed.Param.Key = replacement
+			} else {
+				renamed.Option.Raw = replacement
+			}
+		})
+	}
+
+	if key := lookupGormKey(name); key != nil {
+		if item.Option != nil && key.kind == keyParam {
+			return []finding{{
+				start:    start,
+				end:      end,
+				severity: severityWarning,
+				code:     codeMissingValue,
+				message:  fmt.Sprintf("GORM key %q requires a value", name),
+			}}
+		}
+		return nil
+	}
+
+	suggestion, equivalent := suggestGormKey(name)
+	if equivalent {
+		return []finding{{
+			start:    start,
+			end:      end,
+			severity: severityHint,
+			code:     codeKeySpelling,
+			message:  fmt.Sprintf("GORM key %q is usually spelled %q", name, suggestion),
+			fix:      rename(suggestion),
+		}}
+	}
+
+	unknown := finding{
+		start:    start,
+		end:      end,
+		severity: severityWarning,
+		code:     codeUnknownKey,
+		message:  fmt.Sprintf("unknown GORM key %q", name),
+	}
+	if suggestion != "" {
+		unknown.message += fmt.Sprintf(", did you mean %q?", suggestion)
+		unknown.fix = rename(suggestion)
+	}
+	return []finding{unknown}
+}
+
+// syntaxFinding reports a lexer error on the tag it happened in. A value that
+// is not quoted gets a fix wrapping it in quotes.
+func syntaxFinding(content string, err error) finding {
+	tokens, _ := fogg.Tokenize(content)
+
+	result := finding{
+		end:      len(content),
+		severity: severityError,
+		code:     codeSyntax,
+		message:  err.Error(),
+	}
+	for i, token := range tokens {
+		if token.Kind == fogg.TokenTagName {
+			result.start = token.Start
+		}
+
+		unquoted := token.Kind == fogg.TokenColon && i == len(tokens)-1 && token.End < len(content) && content[token.End] != '"'
+		if unquoted {
+			valueEnd := token.End + strings.IndexByte(content[token.End:]+" ", ' ')
+			result.end = valueEnd
+			result.fix = &fix{
+				title:   "Wrap tag value in quotation marks",
+				content: content[:token.End] + `"` + content[token.End:valueEnd] + `"` + content[valueEnd:],
+			}
+		}
+	}
+	return result
+}
+
+func editFile(content, title string, edit func(file *fogg.File)) *fix {
+	file, err := fogg.ParseFile(content)
+	if err != nil {
+		return nil
+	}
+	edit(file)
+	return &fix{title: title, content: file.String()}
+}
+
+func tagAt(tags []tagLiteral, offset int) (tagLiteral, bool) {
+	for _, tag := range tags {
+		if tag.contains(offset) {
+			return tag, true
+		}
+	}
+	return tagLiteral{}, false
+}
+
+// hover describes the tag name or GORM key under offset, which is relative to
+// the tag content.
+func hover(content string, offset int) (string, int, int, bool) {
+	tokens, _ := fogg.Tokenize(content)
+
+	var (
+		currentTag  string
+		insideValue bool
+		afterColon  bool
+	)
+	for _, token := range tokens {
+		if offset >= token.Start && offset < token.End {
+			return describeToken(token, currentTag, afterColon)
+		}
+
+		switch token.Kind {
+		case fogg.TokenTagName:
+			currentTag = token.Text
+		case fogg.TokenQuote:
+			insideValue = !insideValue
+			afterColon = false
+		case fogg.TokenItemSeparator:
+			afterColon = false
+		case fogg.TokenColon:
+			afterColon = insideValue
+		}
+	}
+	return "", 0, 0, false
+}
+
+func describeToken(token fogg.Token, tag string, afterColon bool) (string, int, int, bool) {
+	if token.Kind == fogg.TokenTagName {
+		if name := lookupTagName(token.Text); name != nil {
+			return fmt.Sprintf("**%s** tag\n\n%s", name.name, name.doc), token.Start, token.End, true
+		}
+		return "", 0, 0, false
+	}
+
+	isKey := token.Kind == fogg.TokenKey || token.Kind == fogg.TokenValue && !afterColon
+	if tag != "gorm" || !isKey {
+		return "", 0, 0, false
+	}
+
+	key := lookupGormKey(token.Text)
+	if suggestion, equivalent := suggestGormKey(token.Text); key == nil && equivalent {
+		key = lookupGormKey(suggestion)
+	}
+	if key == nil {
+		return "", 0, 0, false
+	}
+	return fmt.Sprintf("**%s** (GORM)\n\n%s", key.name, key.doc), token.Start, token.End, true
+}
+
+type completionContext struct {
+	tagName     string
+	insideValue bool
+	key         string
+	hasColon    bool
+	wordStart   int
+}
+
+// completionContextAt inspects the tag content before offset, which may be an
+// incomplete tag that the lexer would reject.
+func completionContextAt(content string, offset int) completionContext {
+	prefix := content[:offset]
+
+	var context completionContext
+	var quotesStack []byte
+	tagStart, itemStart := 0, 0
+
+	for pos := 0; pos < len(prefix); pos++ {
+		char := prefix[pos]
+		switch {
+		case char == '\\':
+			pos++
+		case char == '"' && !context.insideValue:
+			context.insideValue = true
+			itemStart = pos + 1
+		case char == '"' && len(quotesStack) == 0:
+			context.insideValue = false
+		case char == ' ' && !context.insideValue:
+			tagStart = pos + 1
+		case context.insideValue && (char == '\'' || char == '"'):
+			if depth := len(quotesStack); depth > 0 && quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
+			} else {
+				quotesStack = append(quotesStack, char)
+			}
+		case context.insideValue && char == ';' && len(quotesStack) == 0:
+			itemStart = pos + 1
+		}
+	}
+
+	if !context.insideValue {
+		context.wordStart = tagStart
+		context.hasColon = strings.Contains(prefix[tagStart:], ":")
+		return context
+	}
+
+	context.tagName, _, _ = strings.Cut(prefix[tagStart:], ":")
+	item := prefix[itemStart:]
+	context.wordStart = itemStart + len(item) - len(strings.TrimLeft(item, " "))
+	if key, value, found := strings.Cut(item, ":"); found {
+		context.key = strings.TrimSpace(key)
+		context.hasColon = true
+		context.wordStart = offset - len(strings.TrimLeft(value, " "))
+	}
+	return context
+}
+
+func completions(content string, offset int) []completionItemSpec {
+	context := completionContextAt(content, offset)
+
+	var items []completionItemSpec
+	switch {
+	case !context.insideValue && !context.hasColon:
+		for _, name := range tagNames {
+			items = append(items, completionItemSpec{
+				label:   name.name,
+				kind:    completionKindModule,
+				detail:  "struct tag",
+				doc:     name.doc,
+				insert:  name.name + `:"$1"`,
+				snippet: true,
+			})
+		}
+	case context.insideValue && context.tagName == "gorm" && !context.hasColon:
+		for _, key := range gormKeys {
+			item := completionItemSpec{label: key.name, kind: completionKindKeyword, detail: "GORM option", doc: key.doc, insert: key.name}
+			if key.kind&keyParam != 0 {
+				item.kind, item.detail = completionKindProperty, "GORM param"
+			}
+			if key.kind == keyParam {
+				item.insert = key.name + ":"
+			}
+			items = append(items, item)
+		}
+	case context.insideValue && context.tagName == "gorm":
+		if key := lookupGormKey(context.key); key != nil {
+			for _, value := range key.values {
+				items = append(items, completionItemSpec{label: value, kind: completionKindValue, detail: key.name, insert: value})
+			}
+		}
+	}
+
+	for i := range items {
+		items[i].start = context.wordStart
+	}
+	return items
+}
+
+type completionItemSpec struct {
+	label   string
+	kind    int
+	detail  string
+	doc     string
+	insert  string
+	snippet bool
+	start   int
+}
@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"testing"
+)
+
+func TestPositionConversion(t *testing.T) {
+	const text = "line\nпривет 😀 x\n"
+
+	tests := []struct {
+		offset   int
+		position Position
+	}{
+		{0, Position{0, 0}},
+		{4, Position{0, 4}},
+		{5, Position{1, 0}},
+		{17, Position{1, 6}},
+		{18, Position{1, 7}},
+		{22, Position{1, 9}},
+		{len(text), Position{2, 0}},
+	}
+
+	for _, test := range tests {
+		if position := offsetToPosition(text, test.offset); position != test.position {
+			t.Errorf("offsetToPosition(%d) = %+v; want %+v", test.offset, position, test.position)
+		}
+		if offset := positionToOffset(text, test.position); offset != test.offset {
+			t.Errorf("positionToOffset(%+v) = %d; want %d", test.position, offset, test.offset)
+		}
+	}
+
+	if offset := positionToOffset(text, Position{0, 99}); offset != 4 {
+		t.Errorf("expected position past the line end to clamp, got %d", offset)
+	}
+}
+
+func TestSuggestGormKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		suggestion string
+		equivalent bool
+	}{
+		{"PRIMARYKEY", "primaryKey", true},
+		{"NOT NULL", "not null", true},
+		{"not_null", "not null", false},
+		{"foriegnKey", "foreignKey", false},
+		{"uniqeIndex", "uniqueIndex", false},
+		{"xyz", "", false},
+	}
+
+	for _, test := range tests {
+		suggestion, equivalent := suggestGormKey(test.name)
+		if suggestion != test.suggestion || equivalent != test.equivalent {
+			t.Errorf("suggestGormKey(%q) = %q, %v; want %q, %v", test.name, suggestion, equivalent, test.suggestion, test.equivalent)
+		}
+	}
+}
+
+func TestCompletionContext(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected completionContext
+	}{
+		{`js`, completionContext{wordStart: 0}},
+		{`json:"id" go`, completionContext{wordStart: 10}},
+		{`gorm:"not null; pri`, completionContext{tagName: "gorm", insideValue: true, wordStart: 16}},
+		{`gorm:"default:'a;b';serializer: js`, completionContext{tagName: "gorm", insideValue: true, key: "serializer", hasColon: true, wordStart: 32}},
+		{`gorm:"x" json:"a\"b" gorm:"`, completionContext{tagName: "gorm", insideValue: true, wordStart: 27}},
+	}
+
+	for _, test := range tests {
+		if context := completionContextAt(test.content, len(test.content)); context != test.expected {
+			t.Errorf("completionContextAt(%q) = %+v; want %+v", test.content, context, test.expected)
+		}
+	}
+}
+
+func TestFindTags(t *testing.T) {
+	const source = "package p\n\ntype T struct {\n\tA int `json:\"a\"`\n\tB int \"json:\\\"b\\\"\"\n\tC struct {\n\t\tD int `xml:\"d\"`\n\t}\n}\n"
+
+	tags := findTags(source)
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 raw string tags, got %+v", tags)
+	}
+	if tags[0].content != `json:"a"` || source[tags[0].offset:tags[0].offset+len(tags[0].content)] != tags[0].content {
+		t.Errorf("unexpected tag %+v", tags[0])
+	}
+	if tags[1].content != `xml:"d"` {
+		t.Errorf("unexpected tag %+v", tags[1])
+	}
+}
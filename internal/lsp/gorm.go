@@ -0,0 +1,140 @@
+package lsp
+
+import (
+	"strings"
+)
+
+type keyKind int
+
+const (
+	keyOption keyKind = 1 << iota
+	keyParam
+)
+
+type gormKey struct {
+	name   string
+	kind   keyKind
+	doc    string
+	values []string
+}
+
+// gormKeys follows the field tags and association tags listed in the GORM
+// documentation.
+var gormKeys = []gormKey{
+	{name: "column", kind: keyParam, doc: "Column db name."},
+	{name: "type", kind: keyParam, doc: "Column data type, prefer to use compatible general type, e.g: `bool`, `int`, `uint`, `float`, `string`, `time`, `bytes`. A full database data type like `MEDIUMINT UNSIGNED NOT NULL AUTO_INCREMENT` is also supported."},
+	{name: "serializer", kind: keyParam, doc: "Specifies serializer for how to serialize and deserialize data into db, e.g: `serializer:json`.", values: []string{"json", "gob", "unixtime"}},
+	{name: "size", kind: keyParam, doc: "Specifies column data size/length, e.g: `size:256`."},
+	{name: "primaryKey", kind: keyOption, doc: "Specifies column as primary key."},
+	{name: "unique", kind: keyOption, doc: "Specifies column as unique."},
+	{name: "default", kind: keyParam, doc: "Specifies column default value."},
+	{name: "precision", kind: keyParam, doc: "Specifies column precision."},
+	{name: "scale", kind: keyParam, doc: "Specifies column scale."},
+	{name: "not null", kind: keyOption, doc: "Specifies column as NOT NULL."},
+	{name: "autoIncrement", kind: keyOption, doc: "Specifies column auto incrementable."},
+	{name: "autoIncrementIncrement", kind: keyParam, doc: "Auto increment step, controls the interval between successive column values."},
+	{name: "embedded", kind: keyOption, doc: "Embed the field."},
+	{name: "embeddedPrefix", kind: keyParam, doc: "Column name prefix for embedded fields."},
+	{name: "autoCreateTime", kind: keyOption | keyParam, doc: "Track current time when creating. For int fields it tracks unix seconds, use value `nano`/`milli` to track unix nano/milli seconds, e.g: `autoCreateTime:nano`.", values: []string{"nano", "milli"}},
+	{name: "autoUpdateTime", kind: keyOption | keyParam, doc: "Track current time when creating/updating. For int fields it tracks unix seconds, use value `nano`/`milli` to track unix nano/milli seconds, e.g: `autoUpdateTime:milli`.", values: []string{"nano", "milli"}},
+	{name: "index", kind: keyOption | keyParam, doc: "Create index with options, use same name for multiple fields creates composite indexes."},
+	{name: "uniqueIndex", kind: keyOption | keyParam, doc: "Same as `index`, but create uniqued index."},
+	{name: "check", kind: keyParam, doc: "Creates check constraint, e.g: `check:age > 13`."},
+	{name: "<-", kind: keyOption | keyParam, doc: "Set field's write permission: `<-:create` create-only field, `<-:update` update-only field, `<-:false` no write permission, `<-` create and update permission.", values: []string{"create", "update", "false"}},
+	{name: "->", kind: keyOption | keyParam, doc: "Set field's read permission, `->:false` no read permission.", values: []string{"false"}},
+	{name: "-", kind: keyOption | keyParam, doc: "Ignore this field: `-` no read/write permission, `-:migration` no migrate permission, `-:all` no read/write/migrate permission.", values: []string{"migration", "all"}},
+	{name: "comment", kind: keyParam, doc: "Add comment for field when migration."},
+	{name: "foreignKey", kind: keyParam, doc: "Specifies column name of the current model that is used as a foreign key to the join table."},
+	{name: "references", kind: keyParam, doc: "Specifies column name of the reference's table that is mapped to the foreign key of the join table."},
+	{name: "polymorphic", kind: keyParam, doc: "Specifies the polymorphic type such as model name."},
+	{name: "polymorphicValue", kind: keyParam, doc: "Specifies the polymorphic value, default table name."},
+	{name: "many2many", kind: keyParam, doc: "Specifies join table name."},
+	{name: "joinForeignKey", kind: keyParam, doc: "Specifies foreign key column name of join table that maps to the current table."},
+	{name: "joinReferences", kind: keyParam, doc: "Specifies foreign key column name of join table that maps to the reference's table."},
+	{name: "constraint", kind: keyParam, doc: "Relations constraint, e.g: `constraint:OnUpdate:CASCADE,OnDelete:SET NULL`.", values: []string{"OnUpdate:", "OnDelete:"}},
+}
+
+type tagName struct {
+	name string
+	doc  string
+}
+
+var tagNames = []tagName{
+	{name: "json", doc: "Field name and options used by `encoding/json`."},
+	{name: "xml", doc: "Element or attribute name and options used by `encoding/xml`."},
+	{name: "yaml", doc: "Field name and options used by YAML encoders."},
+	{name: "toml", doc: "Field name used by TOML encoders."},
+	{name: "gorm", doc: "GORM column settings and associations, `;` separated."},
+	{name: "validate", doc: "Validation rules of `go-playground/validator`, `,` separated."},
+	{name: "binding", doc: "Validation rules applied by Gin when binding requests."},
+	{name: "form", doc: "Form field name used when binding requests."},
+	{name: "db", doc: "Column name used by `database/sql` mappers such as sqlx."},
+	{name: "mapstructure", doc: "Key used when decoding maps into structs."},
+	{name: "env", doc: "Environment variable bound to the field."},
+	{name: "flag", doc: "Command-line flag bound to the field."},
+	{name: "csv", doc: "CSV column bound to the field."},
+	{name: "protobuf", doc: "Protocol Buffers field description of generated code."},
+}
+
+func lookupGormKey(name string) *gormKey {
+	for i := range gormKeys {
+		if gormKeys[i].name == name {
+			return &gormKeys[i]
+		}
+	}
+	return nil
+}
+
+func lookupTagName(name string) *tagName {
+	for i := range tagNames {
+		if tagNames[i].name == name {
+			return &tagNames[i]
+		}
+	}
+	return nil
+}
+
+// suggestGormKey returns the known key GORM treats name as, or the closest
+// known key to a misspelled name. GORM upper-cases keys, so the spelling is
+// exact only when the returned key equals name.
+func suggestGormKey(name string) (suggestion string, equivalent bool) {
+	normalized := normalizeGormKey(name)
+	best, bestDistance := "", 3
+
+	for _, key := range gormKeys {
+		if strings.EqualFold(key.name, name) {
+			return key.name, true
+		}
+
+		distance := levenshtein(normalized, normalizeGormKey(key.name))
+		if distance < bestDistance && len(normalized) > distance*2 {
+			best, bestDistance = key.name, distance
+		}
+	}
+	return best, false
+}
+
+func normalizeGormKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(name))
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
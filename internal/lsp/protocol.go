@@ -0,0 +1,186 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+const (
+	severityError   = 1
+	severityWarning = 2
+	severityHint    = 4
+)
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+const (
+	completionKindProperty = 10
+	completionKindKeyword  = 14
+	completionKindValue    = 12
+	completionKindModule   = 9
+
+	insertTextFormatSnippet = 2
+)
+
+type CompletionItem struct {
+	Label            string         `json:"label"`
+	Kind             int            `json:"kind,omitempty"`
+	Detail           string         `json:"detail,omitempty"`
+	Documentation    *MarkupContent `json:"documentation,omitempty"`
+	InsertTextFormat int            `json:"insertTextFormat,omitempty"`
+	TextEdit         *TextEdit      `json:"textEdit,omitempty"`
+}
+
+type CompletionList struct {
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type contentChange struct {
+	Range *Range `json:"range,omitempty"`
+	Text  string `json:"text"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentItem `json:"textDocument"`
+	ContentChanges []contentChange  `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     int          `json:"version"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type serverInfo struct {
+	Name string `json:"name"`
+}
+
+type initializeResult struct {
+	Capabilities map[string]any `json:"capabilities"`
+	ServerInfo   serverInfo     `json:"serverInfo"`
+}
+
+// offsetToPosition converts a byte offset in text to an LSP position, whose
+// character counts UTF-16 code units.
+func offsetToPosition(text string, offset int) Position {
+	offset = min(max(offset, 0), len(text))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+
+	character := 0
+	for _, char := range text[lineStart:offset] {
+		character += utf16Len(char)
+	}
+	return Position{Line: strings.Count(text[:lineStart], "\n"), Character: character}
+}
+
+func positionToOffset(text string, position Position) int {
+	offset := 0
+	for line := 0; line < position.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next == -1 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+
+	for character := 0; character < position.Character && offset < len(text); {
+		char, size := utf8.DecodeRuneInString(text[offset:])
+		if char == '\n' {
+			break
+		}
+		character += utf16Len(char)
+		offset += size
+	}
+	return offset
+}
+
+func utf16Len(char rune) int {
+	if char >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func rangeOf(text string, start, end int) Range {
+	return Range{Start: offsetToPosition(text, start), End: offsetToPosition(text, end)}
+}
+
+func (position Position) before(other Position) bool {
+	return position.Line < other.Line || position.Line == other.Line && position.Character < other.Character
+}
+
+func (r Range) overlaps(other Range) bool {
+	return !r.End.before(other.Start) && !other.End.before(r.Start)
+}
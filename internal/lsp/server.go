@@ -0,0 +1,299 @@
+// Package lsp implements a language server for struct tags in Go files. It
+// reports fogg parse errors and GORM key problems as diagnostics, completes
+// tag names and GORM keys, describes GORM keys on hover and offers quick fixes.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+type document struct {
+	text     string
+	version  int
+	tags     []tagLiteral
+	findings []documentFinding
+}
+
+type Server struct {
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+// Serve runs a language server speaking JSON-RPC over in and out until the
+// client sends the exit notification or closes in.
+func Serve(in io.Reader, out io.Writer) error {
+	server := &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: make(map[string]*document),
+	}
+	return server.run()
+}
+
+func (server *Server) run() error {
+	for {
+		body, err := readMessage(server.reader)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var request requestMessage
+		if err := json.Unmarshal(body, &request); err != nil {
+			if err := server.replyError(nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if request.Method == "exit" {
+			if !server.shutdown {
+				return errors.New(exitWithoutShutdownErr)
+			}
+			return nil
+		}
+
+		result, rpcErr := server.handle(request)
+		if request.ID == nil {
+			continue
+		}
+		if rpcErr != nil {
+			err = server.replyError(request.ID, rpcErr)
+		} else {
+			err = writeMessage(server.writer, responseMessage{JSONRPC: "2.0", ID: request.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (server *Server) replyError(id json.RawMessage, rpcErr *responseError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return writeMessage(server.writer, errorMessage{JSONRPC: "2.0", ID: id, Error: rpcErr})
+}
+
+func (server *Server) handle(request requestMessage) (any, *responseError) {
+	if server.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch request.Method {
+	case "initialize":
+		return server.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		server.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		return decode(request.Params, &params, func() (any, error) {
+			return nil, server.open(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+		})
+	case "textDocument/didChange":
+		var params didChangeParams
+		return decode(request.Params, &params, func() (any, error) {
+			return nil, server.change(params)
+		})
+	case "textDocument/didClose":
+		var params didCloseParams
+		return decode(request.Params, &params, func() (any, error) {
+			delete(server.documents, params.TextDocument.URI)
+			return nil, server.publish(params.TextDocument.URI, 0, nil)
+		})
+	case "textDocument/completion":
+		var params positionParams
+		return decode(request.Params, &params, func() (any, error) {
+			return server.completion(params), nil
+		})
+	case "textDocument/hover":
+		var params positionParams
+		return decode(request.Params, &params, func() (any, error) {
+			return server.hover(params), nil
+		})
+	case "textDocument/codeAction":
+		var params codeActionParams
+		return decode(request.Params, &params, func() (any, error) {
+			return server.codeActions(params), nil
+		})
+	}
+
+	if request.ID != nil {
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + request.Method}
+	}
+	return nil, nil
+}
+
+func decode(raw json.RawMessage, params any, handler func() (any, error)) (any, *responseError) {
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	result, err := handler()
+	if err != nil {
+		return nil, &responseError{Code: codeInvalidRequest, Message: err.Error()}
+	}
+	return result, nil
+}
+
+func (server *Server) initialize() initializeResult {
+	return initializeResult{
+		Capabilities: map[string]any{
+			"textDocumentSync": 1,
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"`", `"`, ";", ":", " "},
+			},
+			"hoverProvider": true,
+			"codeActionProvider": map[string]any{
+				"codeActionKinds": []string{"quickfix"},
+			},
+		},
+		ServerInfo: serverInfo{Name: "fogg"},
+	}
+}
+
+func (server *Server) open(uri string, version int, text string) error {
+	doc := &document{text: text, version: version}
+	server.documents[uri] = doc
+	return server.analyze(uri, doc)
+}
+
+func (server *Server) change(params didChangeParams) error {
+	doc, exists := server.documents[params.TextDocument.URI]
+	if !exists {
+		doc = &document{}
+		server.documents[params.TextDocument.URI] = doc
+	}
+
+	for _, change := range params.ContentChanges {
+		if change.Range == nil {
+			doc.text = change.Text
+		} else {
+			start := positionToOffset(doc.text, change.Range.Start)
+			end := positionToOffset(doc.text, change.Range.End)
+			doc.text = doc.text[:start] + change.Text + doc.text[end:]
+		}
+	}
+	doc.version = params.TextDocument.Version
+
+	return server.analyze(params.TextDocument.URI, doc)
+}
+
+func (server *Server) analyze(uri string, doc *document) error {
+	doc.tags = findTags(doc.text)
+	doc.findings = analyze(doc.text, doc.tags)
+
+	diagnostics := make([]Diagnostic, 0, len(doc.findings))
+	for _, finding := range doc.findings {
+		diagnostics = append(diagnostics, finding.diagnostic)
+	}
+	return server.publish(uri, doc.version, diagnostics)
+}
+
+func (server *Server) publish(uri string, version int, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return writeMessage(server.writer, notificationMessage{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Version: version, Diagnostics: diagnostics},
+	})
+}
+
+func (server *Server) completion(params positionParams) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+
+	doc, exists := server.documents[params.TextDocument.URI]
+	if !exists {
+		return list
+	}
+	offset := positionToOffset(doc.text, params.Position)
+	tag, found := tagAt(doc.tags, offset)
+	if !found {
+		return list
+	}
+
+	end := offsetToPosition(doc.text, offset)
+	for _, spec := range completions(tag.content, offset-tag.offset) {
+		item := CompletionItem{
+			Label:  spec.label,
+			Kind:   spec.kind,
+			Detail: spec.detail,
+			TextEdit: &TextEdit{
+				Range:   Range{Start: offsetToPosition(doc.text, tag.offset+spec.start), End: end},
+				NewText: spec.insert,
+			},
+		}
+		if spec.doc != "" {
+			item.Documentation = &MarkupContent{Kind: "markdown", Value: spec.doc}
+		}
+		if spec.snippet {
+			item.InsertTextFormat = insertTextFormatSnippet
+		}
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+func (server *Server) hover(params positionParams) *Hover {
+	doc, exists := server.documents[params.TextDocument.URI]
+	if !exists {
+		return nil
+	}
+	offset := positionToOffset(doc.text, params.Position)
+	tag, found := tagAt(doc.tags, offset)
+	if !found {
+		return nil
+	}
+
+	text, start, end, found := hover(tag.content, offset-tag.offset)
+	if !found {
+		return nil
+	}
+
+	hoverRange := rangeOf(doc.text, tag.offset+start, tag.offset+end)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &hoverRange}
+}
+
+func (server *Server) codeActions(params codeActionParams) []CodeAction {
+	actions := []CodeAction{}
+
+	doc, exists := server.documents[params.TextDocument.URI]
+	if !exists {
+		return actions
+	}
+
+	for _, finding := range doc.findings {
+		if finding.fix == nil || !finding.diagnostic.Range.overlaps(params.Range) {
+			continue
+		}
+
+		edit := TextEdit{
+			Range:   rangeOf(doc.text, finding.tag.offset, finding.tag.offset+len(finding.tag.content)),
+			NewText: finding.fix.content,
+		}
+		actions = append(actions, CodeAction{
+			Title:       finding.fix.title,
+			Kind:        "quickfix",
+			Diagnostics: []Diagnostic{finding.diagnostic},
+			IsPreferred: true,
+			Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{params.TextDocument.URI: {edit}}},
+		})
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Diagnostics[0].Range.Start.before(actions[j].Diagnostics[0].Range.Start)
+	})
+	return actions
+}
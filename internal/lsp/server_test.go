@@ -0,0 +1,392 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testURI = "file:///model.go"
+
+const testSource = "package model\n" +
+	"\n" +
+	"type User struct {\n" +
+	"\tID    uint   `json:\"id\" gorm:\"primarykey\"`\n" +
+	"\tName  string `gorm:\"colum:name;size:64;size:32\"`\n" +
+	"\tEmail string `gorm:\"unique;not null\" json:email`\n" +
+	"\tAge   int    `gorm:\"\"`\n" +
+	"}\n"
+
+type testClient struct {
+	t        *testing.T
+	writer   io.WriteCloser
+	messages chan json.RawMessage
+	done     chan error
+	nextID   int
+	pending  []json.RawMessage
+}
+
+func startServer(t *testing.T) *testClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	client := &testClient{
+		t:        t,
+		writer:   clientOut,
+		messages: make(chan json.RawMessage, 64),
+		done:     make(chan error, 1),
+	}
+
+	go func() {
+		client.done <- Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	go func() {
+		reader := bufio.NewReader(clientIn)
+		for {
+			body, err := readMessage(reader)
+			if err != nil {
+				close(client.messages)
+				return
+			}
+			client.messages <- body
+		}
+	}()
+
+	return client
+}
+
+func (client *testClient) send(message any) {
+	client.t.Helper()
+	if err := writeMessage(client.writer, message); err != nil {
+		client.t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func (client *testClient) notify(method string, params any) {
+	client.t.Helper()
+	client.send(notificationMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (client *testClient) receive() json.RawMessage {
+	client.t.Helper()
+	select {
+	case body, ok := <-client.messages:
+		if !ok {
+			client.t.Fatalf("server closed the connection")
+		}
+		return body
+	case <-time.After(5 * time.Second):
+		client.t.Fatalf("timed out waiting for the server")
+	}
+	return nil
+}
+
+func (client *testClient) call(method string, params any, result any) *responseError {
+	client.t.Helper()
+	client.nextID++
+	id, _ := json.Marshal(client.nextID)
+	client.send(map[string]any{"jsonrpc": "2.0", "id": client.nextID, "method": method, "params": params})
+
+	for {
+		body := client.receive()
+		var response struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *responseError  `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			client.t.Fatalf("invalid message %s: %s", body, err)
+		}
+
+		if !bytes.Equal(response.ID, id) {
+			client.pending = append(client.pending, body)
+			continue
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				client.t.Fatalf("invalid result %s: %s", response.Result, err)
+			}
+		}
+		return nil
+	}
+}
+
+func (client *testClient) diagnostics() publishDiagnosticsParams {
+	client.t.Helper()
+	for {
+		var body json.RawMessage
+		if len(client.pending) > 0 {
+			body, client.pending = client.pending[0], client.pending[1:]
+		} else {
+			body = client.receive()
+		}
+
+		var notification struct {
+			Method string                   `json:"method"`
+			Params publishDiagnosticsParams `json:"params"`
+		}
+		if err := json.Unmarshal(body, &notification); err == nil && notification.Method == "textDocument/publishDiagnostics" {
+			return notification.Params
+		}
+	}
+}
+
+func (client *testClient) initialize() {
+	client.t.Helper()
+	var result initializeResult
+	if err := client.call("initialize", map[string]any{"capabilities": map[string]any{}}, &result); err != nil {
+		client.t.Fatalf("unexpected error: %s", err)
+	}
+	if result.ServerInfo.Name != "fogg" || result.Capabilities["hoverProvider"] != true {
+		client.t.Fatalf("unexpected initialize result %+v", result)
+	}
+	client.notify("initialized", map[string]any{})
+}
+
+func (client *testClient) shutdown() {
+	client.t.Helper()
+	if err := client.call("shutdown", nil, nil); err != nil {
+		client.t.Fatalf("unexpected error: %s", err)
+	}
+	client.notify("exit", nil)
+
+	select {
+	case err := <-client.done:
+		if err != nil {
+			client.t.Errorf("unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		client.t.Fatalf("server did not exit")
+	}
+}
+
+func positionOf(t *testing.T, text, needle string, shift int) Position {
+	t.Helper()
+	offset := strings.Index(text, needle)
+	if offset == -1 {
+		t.Fatalf("%q not found", needle)
+	}
+	return offsetToPosition(text, offset+shift)
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+
+	client.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: testSource}})
+	published := client.diagnostics()
+
+	if published.URI != testURI || published.Version != 1 {
+		t.Errorf("unexpected notification %+v", published)
+	}
+
+	expected := []struct {
+		code     string
+		severity int
+		line     int
+		message  string
+	}{
+		{codeKeySpelling, severityHint, 3, `GORM key "primarykey" is usually spelled "primaryKey"`},
+		{codeUnknownKey, severityWarning, 4, `unknown GORM key "colum", did you mean "column"?`},
+		{codeDuplicateParam, severityError, 4, `duplicated param "size" in tag`},
+		{codeSyntax, severityError, 5, "`json` tag value must be in quotation marks"},
+	}
+	if len(published.Diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %+v", len(expected), published.Diagnostics)
+	}
+	for i, diagnostic := range published.Diagnostics {
+		if diagnostic.Code != expected[i].code || diagnostic.Severity != expected[i].severity ||
+			diagnostic.Range.Start.Line != expected[i].line || diagnostic.Message != expected[i].message {
+			t.Errorf("expected %+v, got %+v", expected[i], diagnostic)
+		}
+	}
+
+	duplicate := published.Diagnostics[2].Range
+	if start := positionOf(t, testSource, "size:32", 0); duplicate.Start != start || duplicate.End.Character != start.Character+4 {
+		t.Errorf("unexpected range of the duplicated param %+v", duplicate)
+	}
+
+	fixed := strings.Replace(testSource, "colum:name", "column:name", 1)
+	client.notify("textDocument/didChange", didChangeParams{
+		TextDocument:   textDocumentItem{URI: testURI, Version: 2},
+		ContentChanges: []contentChange{{Text: fixed}},
+	})
+	if published := client.diagnostics(); published.Version != 2 || len(published.Diagnostics) != 3 {
+		t.Errorf("unexpected diagnostics after change %+v", published)
+	}
+
+	client.notify("textDocument/didClose", didCloseParams{TextDocument: textDocumentIdentifier{URI: testURI}})
+	if published := client.diagnostics(); len(published.Diagnostics) != 0 {
+		t.Errorf("expected diagnostics to be cleared, got %+v", published)
+	}
+
+	client.shutdown()
+}
+
+func TestServerIncrementalChange(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+
+	client.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: testSource}})
+	client.diagnostics()
+
+	start := positionOf(t, testSource, "colum:", 0)
+	client.notify("textDocument/didChange", didChangeParams{
+		TextDocument: textDocumentItem{URI: testURI, Version: 2},
+		ContentChanges: []contentChange{{
+			Range: &Range{Start: start, End: Position{Line: start.Line, Character: start.Character + 5}},
+			Text:  "column",
+		}},
+	})
+	if published := client.diagnostics(); len(published.Diagnostics) != 3 {
+		t.Errorf("unexpected diagnostics after change %+v", published)
+	}
+
+	client.shutdown()
+}
+
+func TestServerCodeActions(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+	client.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: testSource}})
+	client.diagnostics()
+
+	tests := []struct {
+		line     int
+		title    string
+		expected string
+	}{
+		{3, `Replace with "primaryKey"`, `json:"id" gorm:"primaryKey"`},
+		{5, "Wrap tag value in quotation marks", `gorm:"unique;not null" json:"email"`},
+	}
+
+	for _, test := range tests {
+		var actions []CodeAction
+		lineRange := Range{Start: Position{Line: test.line}, End: Position{Line: test.line, Character: 80}}
+		err := client.call("textDocument/codeAction", codeActionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Range: lineRange}, &actions)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(actions) != 1 || actions[0].Title != test.title || actions[0].Kind != "quickfix" {
+			t.Fatalf("unexpected actions %+v", actions)
+		}
+		edits := actions[0].Edit.Changes[testURI]
+		if len(edits) != 1 || edits[0].NewText != test.expected {
+			t.Errorf("unexpected edits %+v", edits)
+		}
+	}
+
+	var actions []CodeAction
+	lineRange := Range{Start: Position{Line: 4}, End: Position{Line: 4, Character: 80}}
+	if err := client.call("textDocument/codeAction", codeActionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Range: lineRange}, &actions); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(actions) != 2 || actions[1].Edit.Changes[testURI][0].NewText != `gorm:"colum:name;size:64"` {
+		t.Errorf("unexpected actions %+v", actions)
+	}
+
+	client.shutdown()
+}
+
+func TestServerCompletion(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+	client.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: testSource}})
+	client.diagnostics()
+
+	var list CompletionList
+	position := positionOf(t, testSource, `gorm:""`, len(`gorm:"`))
+	err := client.call("textDocument/completion", positionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: position}, &list)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	labels := make(map[string]CompletionItem)
+	for _, item := range list.Items {
+		labels[item.Label] = item
+	}
+	if item, exists := labels["primaryKey"]; !exists || item.TextEdit.NewText != "primaryKey" || item.TextEdit.Range.Start != position {
+		t.Errorf("expected primaryKey completion, got %+v", item)
+	}
+	if item, exists := labels["column"]; !exists || item.TextEdit.NewText != "column:" || item.Documentation == nil {
+		t.Errorf("expected column completion, got %+v", item)
+	}
+
+	position = positionOf(t, testSource, "`json", 1)
+	if err := client.call("textDocument/completion", positionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: position}, &list); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.Items) != len(tagNames) || list.Items[0].InsertTextFormat != insertTextFormatSnippet {
+		t.Errorf("expected tag name completions, got %+v", list.Items)
+	}
+
+	client.shutdown()
+}
+
+func TestServerHover(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+	client.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: testSource}})
+	client.diagnostics()
+
+	var result *Hover
+	position := positionOf(t, testSource, "not null", 2)
+	err := client.call("textDocument/hover", positionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: position}, &result)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result == nil || !strings.Contains(result.Contents.Value, "NOT NULL") || result.Range.Start.Character != position.Character-2 {
+		t.Errorf("unexpected hover %+v", result)
+	}
+
+	result = nil
+	position = positionOf(t, testSource, "size:64", 0)
+	if err := client.call("textDocument/hover", positionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: position}, &result); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result == nil || !strings.Contains(result.Contents.Value, "**size** (GORM)") {
+		t.Errorf("unexpected hover %+v", result)
+	}
+
+	result = nil
+	position = positionOf(t, testSource, "type User", 0)
+	if err := client.call("textDocument/hover", positionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: position}, &result); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != nil {
+		t.Errorf("expected no hover outside of tags, got %+v", result)
+	}
+
+	client.shutdown()
+}
+
+func TestServerProtocolErrors(t *testing.T) {
+	client := startServer(t)
+	client.initialize()
+
+	if err := client.call("workspace/symbol", map[string]any{}, nil); err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("expected method not found error, got %v", err)
+	}
+	if err := client.call("textDocument/hover", []int{1}, nil); err == nil || err.Code != codeInvalidParams {
+		t.Errorf("expected invalid params error, got %v", err)
+	}
+
+	client.notify("exit", nil)
+	select {
+	case err := <-client.done:
+		if err == nil || err.Error() != exitWithoutShutdownErr {
+			t.Errorf("expected error `%s`, got %v", exitWithoutShutdownErr, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not exit")
+	}
+}
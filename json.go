@@ -0,0 +1,120 @@
+package fogg
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// JSONField describes a struct field the way encoding/json resolves its tag.
+type JSONField struct {
+	// Name is the key of the field in JSON objects. ParseJSONField leaves it
+	// empty when the tag has no valid name, JSONFieldOf then uses the Go name.
+	Name string
+	// Tagged reports whether Name comes from the tag.
+	Tagged bool
+	// Ignored is set by the `json:"-"` tag only, `json:"-,"` names the field "-".
+	Ignored   bool
+	OmitEmpty bool
+	OmitZero  bool
+	// String is set by the "string" option. JSONFieldOf keeps it only for
+	// boolean, numeric and string fields, as encoding/json does.
+	String bool
+}
+
+// ParseJSONField parses the content of a json tag.
+func ParseJSONField(content string) JSONField {
+	const optionsDelimiter = ","
+
+	if content == "-" {
+		return JSONField{Ignored: true}
+	}
+
+	name, options, _ := strings.Cut(content, optionsDelimiter)
+
+	field := JSONField{}
+	if isValidJSONName(name) {
+		field.Name = name
+		field.Tagged = true
+	}
+
+	for options != "" {
+		var option string
+		option, options, _ = strings.Cut(options, optionsDelimiter)
+		switch option {
+		case "omitempty":
+			field.OmitEmpty = true
+		case "omitzero":
+			field.OmitZero = true
+		case "string":
+			field.String = true
+		}
+	}
+
+	return field
+}
+
+// JSONFieldOf resolves the json tag of a struct field. It reports false when
+// encoding/json does not encode the field under a key of its own: the field is
+// ignored, unexported, or an untagged embedded struct whose fields are promoted.
+// Names are validated as in the original encoding/json, its implementation on
+// top of json/v2 reads names with quotes or symbols differently.
+func JSONFieldOf(field reflect.StructField) (JSONField, bool) {
+	if field.Anonymous {
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if !field.IsExported() && embedded.Kind() != reflect.Struct {
+			return JSONField{}, false
+		}
+	} else if !field.IsExported() {
+		return JSONField{}, false
+	}
+
+	jsonField := ParseJSONField(field.Tag.Get("json"))
+	if jsonField.Ignored {
+		return jsonField, false
+	}
+
+	fieldType := field.Type
+	if fieldType.Name() == "" && fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	if jsonField.String {
+		switch fieldType.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64,
+			reflect.String:
+		default:
+			jsonField.String = false
+		}
+	}
+
+	if !jsonField.Tagged {
+		if field.Anonymous && fieldType.Kind() == reflect.Struct {
+			return jsonField, false
+		}
+		jsonField.Name = field.Name
+	}
+
+	return jsonField, true
+}
+
+// isValidJSONName follows the rules encoding/json applies to names in tags.
+func isValidJSONName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", char):
+		case !unicode.IsLetter(char) && !unicode.IsDigit(char):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package fogg
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseJSONField(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected JSONField
+	}{
+		{``, JSONField{}},
+		{`name`, JSONField{Name: "name", Tagged: true}},
+		{`-`, JSONField{Ignored: true}},
+		{`-,`, JSONField{Name: "-", Tagged: true}},
+		{`-,omitempty`, JSONField{Name: "-", Tagged: true, OmitEmpty: true}},
+		{`,omitempty`, JSONField{OmitEmpty: true}},
+		{`name,omitempty,omitzero,string`, JSONField{Name: "name", Tagged: true, OmitEmpty: true, OmitZero: true, String: true}},
+		{`name, omitempty`, JSONField{Name: "name", Tagged: true}},
+		{`name,OmitEmpty`, JSONField{Name: "name", Tagged: true}},
+		{`name,unknown`, JSONField{Name: "name", Tagged: true}},
+		{`a b.c-d`, JSONField{Name: "a b.c-d", Tagged: true}},
+		{`na'me,string`, JSONField{String: true}},
+		{`a\b`, JSONField{}},
+		{`имя`, JSONField{Name: "имя", Tagged: true}},
+		{`🙂`, JSONField{}},
+	}
+
+	for _, test := range tests {
+		if field := ParseJSONField(test.content); field != test.expected {
+			t.Errorf("ParseJSONField(%q) = %+v; want %+v", test.content, field, test.expected)
+		}
+	}
+}
+
+func jsonSupportsOmitZero() bool {
+	data, _ := json.Marshal(struct {
+		Field int `json:",omitzero"`
+	}{})
+	return string(data) == "{}"
+}
+
+// jsonIgnoresInvalidNames reports whether encoding/json falls back to the Go
+// field name for invalid tag names. Its implementation on top of json/v2 reads
+// such names differently.
+func jsonIgnoresInvalidNames() bool {
+	data, _ := json.Marshal(struct {
+		Field int `json:"a'b"`
+	}{})
+	return string(data) == `{"Field":0}`
+}
+
+func isEmptyJSONValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return value.IsZero()
+	}
+	return false
+}
+
+func TestJSONFieldOfMatchesEncodingJSON(t *testing.T) {
+	one := 1
+	tests := []struct {
+		tag         reflect.StructTag
+		values      []any
+		invalidName bool
+	}{
+		{`json:"name"`, []any{0, 1}, false},
+		{`json:"name,omitempty"`, []any{0, 1}, false},
+		{`json:",omitempty"`, []any{"", "x"}, false},
+		{`json:""`, []any{1}, false},
+		{`xml:"name"`, []any{1}, false},
+		{`json:"-"`, []any{1}, false},
+		{`json:"-,"`, []any{1}, false},
+		{`json:"-,omitempty"`, []any{0, 1}, false},
+		{`json:"name, omitempty"`, []any{0}, false},
+		{`json:"name,unknown"`, []any{1}, false},
+		{`json:"a b.c-d"`, []any{1}, false},
+		{`json:"<a&b>"`, []any{1}, false},
+		{`json:"na'me"`, []any{1}, true},
+		{`json:"a\\b"`, []any{1}, true},
+		{`json:"a\"b"`, []any{1}, true},
+		{`json:"имя"`, []any{1}, false},
+		{`json:"🙂"`, []any{1}, true},
+		{`json:"name,string"`, []any{1, "x", true, 1.5, []int{1}, &one, (*int)(nil)}, false},
+		{`json:"name,omitempty,string"`, []any{0, "", false}, false},
+		{`json:"name,omitzero"`, []any{0, []int{}, []int(nil), map[string]int{}, (*int)(nil)}, false},
+		{`json:"name,omitempty"`, []any{[]int{}, []int(nil), map[string]int{}, (*int)(nil), &one}, false},
+		{`json:"name,omitempty,omitzero"`, []any{[]int{}, 0, 1}, false},
+	}
+
+	omitZero := jsonSupportsOmitZero()
+	invalidNames := jsonIgnoresInvalidNames()
+
+	for _, test := range tests {
+		if test.invalidName && !invalidNames {
+			t.Logf("skipping tag %s, encoding/json reads invalid names the json/v2 way", test.tag)
+			continue
+		}
+		for _, value := range test.values {
+			structType := reflect.StructOf([]reflect.StructField{{Name: "Field", Type: reflect.TypeOf(value), Tag: test.tag}})
+			structValue := reflect.New(structType).Elem()
+			structValue.Field(0).Set(reflect.ValueOf(value))
+
+			data, err := json.Marshal(structValue.Interface())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			field, ok := JSONFieldOf(structType.Field(0))
+			fieldValue := structValue.Field(0)
+			omitted := !ok ||
+				(field.OmitEmpty && isEmptyJSONValue(fieldValue)) ||
+				(field.OmitZero && omitZero && fieldValue.IsZero())
+
+			expected := "{}"
+			if !omitted {
+				key, _ := json.Marshal(field.Name)
+				encoded, _ := json.Marshal(value)
+				if field.String && !(fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil()) {
+					encoded, _ = json.Marshal(string(encoded))
+				}
+				expected = "{" + string(key) + ":" + string(encoded) + "}"
+			}
+
+			if string(data) != expected {
+				t.Errorf("tag %s with %#v: JSONFieldOf gives %+v, %v so expected %s, encoding/json gives %s", test.tag, value, field, ok, expected, data)
+			}
+		}
+	}
+}
+
+type JSONEmbedded struct {
+	Inner int
+}
+
+type jsonEmbedded struct {
+	Hidden int
+}
+
+type jsonText string
+
+type jsonResolution struct {
+	JSONEmbedded
+	jsonEmbedded `json:"named"`
+	jsonText
+	hidden  int
+	Skipped int `json:"-"`
+	Dash    int `json:"-,"`
+	Plain   int
+}
+
+func TestJSONFieldOfStruct(t *testing.T) {
+	expected := []struct {
+		name string
+		ok   bool
+	}{
+		{"", false},
+		{"named", true},
+		{"", false},
+		{"", false},
+		{"", false},
+		{"-", true},
+		{"Plain", true},
+	}
+
+	structType := reflect.TypeOf(jsonResolution{})
+	keys := []string{"Inner"}
+	for i, test := range expected {
+		field, ok := JSONFieldOf(structType.Field(i))
+		if ok != test.ok || (ok && field.Name != test.name) {
+			t.Errorf("field %s: got %+v, %v; want name %q, %v", structType.Field(i).Name, field, ok, test.name, test.ok)
+		}
+		if ok {
+			keys = append(keys, field.Name)
+		}
+	}
+
+	data, err := json.Marshal(jsonResolution{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	encodedKeys := make([]string, 0, len(object))
+	for key := range object {
+		encodedKeys = append(encodedKeys, key)
+	}
+	sort.Strings(keys)
+	sort.Strings(encodedKeys)
+	if !reflect.DeepEqual(keys, encodedKeys) {
+		t.Errorf("expected keys %v, encoding/json gives %v", keys, encodedKeys)
+	}
+}
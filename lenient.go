@@ -0,0 +1,98 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Warning is a deviation from the tag syntax, accepted when parsing with
+// WithLenient.
+type Warning struct {
+	Span
+	Message string
+}
+
+// splitLenientTags splits tags separated by any whitespace and quotes bare
+// values, so that every tag is returned as `name:"value"`. Tags without a value
+// are returned as they are and left to Parse to report.
+func splitLenientTags(content string) ([]string, []Warning, error) {
+	var (
+		tags     []string
+		warnings []Warning
+	)
+
+	pos := 0
+	for pos < len(content) {
+		start := pos
+		for pos < len(content) && isTagSpace(content[pos]) {
+			pos++
+		}
+
+		switch separator := content[start:pos]; {
+		case start == 0 && pos > 0:
+			warnings = append(warnings, Warning{Span{start, pos}, leadingSpaceWarn})
+		case pos == len(content):
+			warnings = append(warnings, Warning{Span{start, pos}, trailingSpaceWarn})
+		case start > 0 && separator == "":
+			warnings = append(warnings, Warning{Span{start, pos}, fmt.Sprintf(missingSeparatorWarn, tagNameAt(content, pos))})
+		case start > 0 && separator != string(tagDelimiter):
+			warnings = append(warnings, Warning{Span{start, pos}, fmt.Sprintf(separatorWarn, separator)})
+		}
+		if pos == len(content) {
+			break
+		}
+
+		tagStart := pos
+		name := tagNameAt(content, pos)
+		pos += len(name)
+		if pos == len(content) || content[pos] != tagNameDelimiter {
+			tags = append(tags, name)
+			continue
+		}
+		pos++
+
+		if pos < len(content) && content[pos] == tagBacktick {
+			for pos++; pos < len(content) && content[pos] != tagBacktick; pos++ {
+				if content[pos] == escapeBackslash {
+					pos++
+				}
+			}
+			if pos >= len(content) {
+				return nil, warnings, errors.New(unclosedBacktickErr)
+			}
+			pos++
+			// Well-formed tags are scanned as Parse does without WithLenient
+			tag, err := splitTagItems(content[tagStart:pos], true, []string{string(tagBacktick)}, []string{string(tagDelimiter)}, false)
+			if err != nil {
+				return nil, warnings, err
+			}
+			tags = append(tags, tag...)
+		} else {
+			valueStart := pos
+			for pos < len(content) && !isTagSpace(content[pos]) {
+				pos++
+			}
+			warnings = append(warnings, Warning{Span{tagStart, pos}, fmt.Sprintf(unquotedValueWarn, name)})
+			tags = append(tags, name+string(tagNameDelimiter)+string(tagBacktick)+content[valueStart:pos]+string(tagBacktick))
+		}
+	}
+	return tags, warnings, nil
+}
+
+// tagNameAt returns the name of the tag starting at pos.
+func tagNameAt(content string, pos int) string {
+	end := pos
+	for end < len(content) && content[end] != tagNameDelimiter && !isTagSpace(content[end]) {
+		end++
+	}
+	return content[pos:end]
+}
+
+func isTagSpace(char byte) bool {
+	switch char {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	default:
+		return false
+	}
+}
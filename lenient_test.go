@@ -0,0 +1,114 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+		warnings []Warning
+	}{
+		{
+			`json:"id" gorm:"column:id"`,
+			`json:"id" gorm:"column:id"`,
+			nil,
+		},
+		{
+			"json:\"id\"\tgorm:\"column:id\"",
+			`json:"id" gorm:"column:id"`,
+			[]Warning{{Span{9, 10}, `tags separated by "\t" instead of a single space`}},
+		},
+		{
+			"json:\"id\"\n  gorm:\"column:id\"  ",
+			`json:"id" gorm:"column:id"`,
+			[]Warning{
+				{Span{9, 12}, `tags separated by "\n  " instead of a single space`},
+				{Span{28, 30}, `whitespace after the last tag`},
+			},
+		},
+		{
+			`  json:"id"   gorm:"not null"`,
+			`json:"id" gorm:"not null"`,
+			[]Warning{
+				{Span{0, 2}, `whitespace before the first tag`},
+				{Span{11, 14}, `tags separated by "   " instead of a single space`},
+			},
+		},
+		{
+			`json:id gorm:"default:'a b'" env:PORT`,
+			`json:"id" gorm:"default:'a b'" env:"PORT"`,
+			[]Warning{
+				{Span{0, 7}, "`json` tag value is not in quotation marks"},
+				{Span{29, 37}, "`env` tag value is not in quotation marks"},
+			},
+		},
+		{
+			`json:"a\"b"gorm:"x"`,
+			`json:"a\"b" gorm:"x"`,
+			[]Warning{{Span{11, 11}, `no space before the "gorm" tag`}},
+		},
+	}
+
+	for _, test := range tests {
+		storage, err := Parse(test.tag, WithLenient())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.tag, err)
+			continue
+		}
+		if written := storage.String(); written != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.tag, written, test.expected)
+		}
+		if !reflect.DeepEqual(storage.Warnings(), test.warnings) {
+			t.Errorf("%q: got warnings %+v, expected %+v", test.tag, storage.Warnings(), test.warnings)
+		}
+	}
+}
+
+func TestParseLenientMatchesDefault(t *testing.T) {
+	tags := []string{
+		`json:"id" gorm:"column:id"`,
+		`gorm:"comment:a\ b;default:'x\ y'"`,
+		`gorm:"default:\"x\"" json:"-"`,
+		`gorm:"path:C:\\dir;size:2" validate:"required"`,
+		`gorm:"" json:","`,
+	}
+
+	for _, tag := range tags {
+		expected, err := Parse(tag)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %s", tag, err)
+		}
+		storage, err := Parse(tag, WithLenient())
+		if err != nil {
+			t.Errorf("Parse(%q, WithLenient()): unexpected error: %s", tag, err)
+		} else if !reflect.DeepEqual(storage, expected) {
+			t.Errorf("Parse(%q, WithLenient()) = %+v; want %+v", tag, storage, expected)
+		}
+	}
+}
+
+func TestParseLenientErrors(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`json:"id`, unclosedBacktickErr},
+		{`json:"id\"`, unclosedBacktickErr},
+		{"json:\"id\"\tgorm", "Invalid `gorm` tag syntax"},
+		{`json:"a" json:b`, `duplicated tags with name "json"`},
+		{`gorm:default:'a`, unclosedBacktickErr},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(test.tag, WithLenient()); err == nil || err.Error() != test.expected {
+			t.Errorf("%q: got error %v, expected %q", test.tag, err, test.expected)
+		}
+	}
+
+	if storage, _ := Parse("json:\"id\"\tgorm:\"column:id\""); storage.HasTag("gorm") {
+		t.Errorf("expected tags separated by a tab to be read as one without WithLenient")
+	}
+}
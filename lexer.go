@@ -0,0 +1,412 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenKind int
+
+const (
+	TokenTagName TokenKind = iota
+	TokenColon
+	TokenQuotedString
+	TokenItemSeparator
+	TokenKey
+	TokenValue
+	TokenArgSeparator
+	TokenQuote
+	TokenEscape
+	TokenWhitespace
+)
+
+var tokenKindNames = [...]string{
+	TokenTagName:       "TagName",
+	TokenColon:         "Colon",
+	TokenQuotedString:  "QuotedString",
+	TokenItemSeparator: "ItemSeparator",
+	TokenKey:           "Key",
+	TokenValue:         "Value",
+	TokenArgSeparator:  "ArgSeparator",
+	TokenQuote:         "Quote",
+	TokenEscape:        "Escape",
+	TokenWhitespace:    "Whitespace",
+}
+
+func (kind TokenKind) String() string {
+	if kind >= 0 && int(kind) < len(tokenKindNames) {
+		return tokenKindNames[kind]
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(kind))
+}
+
+// Token is a lexeme of a tag. Start and End are byte offsets into the lexed
+// string, Text is the token with escape sequences and surrounding quotes
+// removed. Tokens are contiguous: together they cover the whole input.
+type Token struct {
+	Kind  TokenKind
+	Start int
+	End   int
+	Text  string
+}
+
+// Lexer splits tags into tokens using the same rules as Parse and ParseSubtag.
+type Lexer struct {
+	content string
+	pos     int
+	subtag  bool
+	escapes byteSet
+	quotes  byteSet
+	tokens  []Token
+	err     error
+}
+
+const (
+	tagBacktick         byte = '"'
+	tagDelimiter        byte = ' '
+	tagNameDelimiter    byte = ':'
+	subtagDelimiter     byte = ';'
+	subtagArgsDelimiter byte = ','
+	escapeBackslash     byte = '\\'
+)
+
+var (
+	subtagQuotes   = newByteSet([]string{`'`, `"`})
+	tagTextEscapes = newByteSet([]string{`\`, `'`, `"`, `;`, ` `})
+	subtagEscapes  = newByteSet([]string{`\`, `'`, `"`, `;`})
+)
+
+// NewLexer returns a lexer for a whole struct tag such as `gorm:"not null"`.
+func NewLexer(content string) *Lexer {
+	return &Lexer{
+		content: content,
+		escapes: tagTextEscapes,
+		quotes:  subtagQuotes,
+	}
+}
+
+// NewSubtagLexer returns a lexer for the content of a single tag, as accepted
+// by ParseSubtag.
+func NewSubtagLexer(content string) *Lexer {
+	return &Lexer{
+		content: content,
+		subtag:  true,
+		escapes: subtagEscapes,
+		quotes:  subtagQuotes,
+	}
+}
+
+// Tokenize returns all tokens of a whole struct tag.
+func Tokenize(content string) ([]Token, error) {
+	return NewLexer(content).All()
+}
+
+// Next returns the next token, or io.EOF once the input is exhausted.
+func (lexer *Lexer) Next() (Token, error) {
+	for len(lexer.tokens) == 0 {
+		if lexer.err != nil {
+			return Token{}, lexer.err
+		}
+		if lexer.pos >= len(lexer.content) {
+			return Token{}, io.EOF
+		}
+		if lexer.subtag {
+			lexer.err = lexer.lexSubtag(0, len(lexer.content))
+			lexer.pos = len(lexer.content)
+		} else {
+			lexer.err = lexer.lexTag()
+		}
+	}
+
+	token := lexer.tokens[0]
+	lexer.tokens = lexer.tokens[1:]
+	return token, nil
+}
+
+// All returns the remaining tokens.
+func (lexer *Lexer) All() ([]Token, error) {
+	var tokens []Token
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return tokens, nil
+		} else if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func (lexer *Lexer) emit(kind TokenKind, start, end int, text string) {
+	if start < end {
+		lexer.tokens = append(lexer.tokens, Token{Kind: kind, Start: start, End: end, Text: text})
+	}
+}
+
+func (lexer *Lexer) emitRaw(kind TokenKind, start, end int) {
+	lexer.emit(kind, start, end, lexer.content[start:end])
+}
+
+func (lexer *Lexer) lexTag() error {
+	content := lexer.content
+	start := lexer.pos
+
+	for start < len(content) && content[start] == tagDelimiter {
+		start++
+	}
+	lexer.emitRaw(TokenWhitespace, lexer.pos, start)
+
+	end := start
+	quoted := false
+	for ; end < len(content); end++ {
+		char := content[end]
+		if char == escapeBackslash && end+1 < len(content) {
+			end++
+		} else if char == tagBacktick {
+			quoted = !quoted
+		} else if char == tagDelimiter && !quoted {
+			break
+		}
+	}
+	lexer.pos = end
+
+	if start == end {
+		return nil
+	}
+	if quoted {
+		return errors.New(unclosedBacktickErr)
+	}
+
+	itemStart, itemEnd := trimTagOffsets(content, start, end)
+	lexer.emitRaw(TokenWhitespace, start, itemStart)
+
+	colon := strings.IndexByte(content[itemStart:itemEnd], tagNameDelimiter)
+	nameEnd := itemEnd
+	if colon != -1 {
+		nameEnd = itemStart + colon
+	}
+	name := unescapeTagName(content[itemStart:nameEnd])
+	lexer.emit(TokenTagName, itemStart, nameEnd, name)
+
+	if colon == -1 || itemEnd-nameEnd-1 < 2 {
+		return errors.New(fmt.Sprintf(valueLessTagErr, name))
+	}
+	valueStart := nameEnd + 1
+	lexer.emitRaw(TokenColon, nameEnd, valueStart)
+
+	if content[valueStart] != tagBacktick || content[itemEnd-1] != tagBacktick {
+		return errors.New(fmt.Sprintf(nonQuotedValueErr, name))
+	}
+
+	lexer.emitRaw(TokenQuote, valueStart, valueStart+1)
+	if err := lexer.lexSubtag(valueStart+1, itemEnd-1); err != nil {
+		return err
+	}
+	lexer.emitRaw(TokenQuote, itemEnd-1, itemEnd)
+	lexer.emitRaw(TokenWhitespace, itemEnd, end)
+
+	return nil
+}
+
+func (lexer *Lexer) lexSubtag(start, end int) error {
+	content := lexer.content
+	var quotesStack []byte
+
+	itemStart := start
+	for pos := start; pos < end; pos++ {
+		char := content[pos]
+		switch {
+		case char == escapeBackslash && pos+1 < end:
+			if lexer.escapes[content[pos+1]] {
+				pos++
+			}
+		case lexer.quotes[char]:
+			if depth := len(quotesStack); depth > 0 && quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
+			} else {
+				quotesStack = append(quotesStack, char)
+			}
+		case char == subtagDelimiter && len(quotesStack) == 0:
+			lexer.lexItem(itemStart, pos)
+			lexer.emitRaw(TokenItemSeparator, pos, pos+1)
+			itemStart = pos + 1
+		}
+	}
+
+	if len(quotesStack) != 0 {
+		return errors.New(unclosedBacktickErr)
+	}
+	lexer.lexItem(itemStart, end)
+
+	return nil
+}
+
+func (lexer *Lexer) lexItem(start, end int) {
+	content := lexer.content
+	itemStart, itemEnd := trimSpaceOffsets(content, start, end)
+	lexer.emitRaw(TokenWhitespace, start, itemStart)
+
+	colon := strings.IndexByte(content[itemStart:itemEnd], tagNameDelimiter)
+	if colon == -1 {
+		lexer.lexValue(itemStart, itemEnd, false)
+	} else {
+		colon += itemStart
+		keyStart, keyEnd := trimSpaceOffsets(content, itemStart, colon)
+		lexer.emit(TokenKey, keyStart, keyEnd, unescapeText(content[keyStart:keyEnd], lexer.escapes))
+		lexer.emitRaw(TokenWhitespace, keyEnd, colon)
+		lexer.emitRaw(TokenColon, colon, colon+1)
+
+		valueStart, valueEnd := trimSpaceOffsets(content, colon+1, itemEnd)
+		lexer.emitRaw(TokenWhitespace, colon+1, valueStart)
+		lexer.lexValue(valueStart, valueEnd, true)
+	}
+
+	lexer.emitRaw(TokenWhitespace, itemEnd, end)
+}
+
+func (lexer *Lexer) lexValue(start, end int, hasArgs bool) {
+	content := lexer.content
+
+	runStart := start
+	for pos := start; pos < end; pos++ {
+		char := content[pos]
+		switch {
+		case char == escapeBackslash && pos+1 < end && lexer.escapes[content[pos+1]]:
+			lexer.emitRaw(TokenValue, runStart, pos)
+			lexer.emit(TokenEscape, pos, pos+2, content[pos+1:pos+2])
+			pos++
+			runStart = pos + 1
+		case lexer.quotes[char]:
+			closing := lexer.closingQuote(pos, end)
+			if closing == -1 {
+				// Args are not split after a quote left open, as in Parse
+				hasArgs = false
+				continue
+			}
+			lexer.emitRaw(TokenValue, runStart, pos)
+			lexer.emit(TokenQuotedString, pos, closing+1, unescapeText(content[pos+1:closing], lexer.escapes))
+			pos = closing
+			runStart = pos + 1
+		case char == subtagArgsDelimiter && hasArgs:
+			lexer.emitRaw(TokenValue, runStart, pos)
+			lexer.emitRaw(TokenArgSeparator, pos, pos+1)
+			runStart = pos + 1
+		}
+	}
+	lexer.emitRaw(TokenValue, runStart, end)
+}
+
+// closingQuote returns the offset of the quote that closes the one at start,
+// or -1 when the quote is not closed before end.
+func (lexer *Lexer) closingQuote(start, end int) int {
+	content := lexer.content
+	quotesStack := []byte{content[start]}
+
+	for pos := start + 1; pos < end; pos++ {
+		char := content[pos]
+		if char == escapeBackslash && pos+1 < end && lexer.escapes[content[pos+1]] {
+			pos++
+		} else if lexer.quotes[char] {
+			if depth := len(quotesStack); quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
+				if depth == 1 {
+					return pos
+				}
+			} else {
+				quotesStack = append(quotesStack, char)
+			}
+		}
+	}
+	return -1
+}
+
+func unescapeText(content string, escapes byteSet) string {
+	if strings.IndexByte(content, escapeBackslash) == -1 {
+		return content
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(content))
+	for pos := 0; pos < len(content); pos++ {
+		if content[pos] == escapeBackslash && pos+1 < len(content) && escapes[content[pos+1]] {
+			pos++
+		}
+		builder.WriteByte(content[pos])
+	}
+	return builder.String()
+}
+
+// trimTagOffsets trims spaces around a tag, escaped ones included, as Parse
+// trims tags once their escaped spaces are unescaped.
+func trimTagOffsets(content string, start, end int) (int, int) {
+	for {
+		switch {
+		case end-start >= 2 && content[start] == escapeBackslash && content[start+1] == tagDelimiter:
+			start += 2
+		case end-start >= 2 && content[end-1] == tagDelimiter && isEscapingBackslash(content, start, end-2):
+			end -= 2
+		default:
+			trimmedStart, trimmedEnd := trimSpaceOffsets(content, start, end)
+			if trimmedStart == start && trimmedEnd == end {
+				return start, end
+			}
+			start, end = trimmedStart, trimmedEnd
+		}
+	}
+}
+
+// isEscapingBackslash reports whether the byte at pos is a backslash escaping
+// the next one, counting the backslashes before it from start.
+func isEscapingBackslash(content string, start, pos int) bool {
+	backslashes := 0
+	for ; pos >= start && content[pos] == escapeBackslash; pos-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// unescapeTagName removes the backslashes of escaped spaces only, keeping the
+// other escape sequences whole as Parse does.
+func unescapeTagName(content string) string {
+	if strings.IndexByte(content, escapeBackslash) == -1 {
+		return content
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(content))
+	for pos := 0; pos < len(content); pos++ {
+		if content[pos] == escapeBackslash && pos+1 < len(content) {
+			switch content[pos+1] {
+			case tagDelimiter:
+				pos++
+			case escapeBackslash, tagBacktick:
+				builder.WriteByte(content[pos])
+				pos++
+			}
+		}
+		builder.WriteByte(content[pos])
+	}
+	return builder.String()
+}
+
+func trimSpaceOffsets(content string, start, end int) (int, int) {
+	for start < end {
+		char, size := utf8.DecodeRuneInString(content[start:end])
+		if !unicode.IsSpace(char) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		char, size := utf8.DecodeLastRuneInString(content[start:end])
+		if !unicode.IsSpace(char) {
+			break
+		}
+		end -= size
+	}
+	return start, end
+}
@@ -0,0 +1,378 @@
+package fogg
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type kindText struct {
+	kind TokenKind
+	text string
+}
+
+func kindTexts(tokens []Token) []kindText {
+	result := make([]kindText, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, kindText{token.Kind, token.Text})
+	}
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	const tag = `json:"name" gorm:"default:'a;b'; not null;index:,unique;check:x\;y"`
+	expected := []kindText{
+		{TokenTagName, "json"},
+		{TokenColon, ":"},
+		{TokenQuote, `"`},
+		{TokenValue, "name"},
+		{TokenQuote, `"`},
+		{TokenWhitespace, " "},
+		{TokenTagName, "gorm"},
+		{TokenColon, ":"},
+		{TokenQuote, `"`},
+		{TokenKey, "default"},
+		{TokenColon, ":"},
+		{TokenQuotedString, "a;b"},
+		{TokenItemSeparator, ";"},
+		{TokenWhitespace, " "},
+		{TokenValue, "not null"},
+		{TokenItemSeparator, ";"},
+		{TokenKey, "index"},
+		{TokenColon, ":"},
+		{TokenArgSeparator, ","},
+		{TokenValue, "unique"},
+		{TokenItemSeparator, ";"},
+		{TokenKey, "check"},
+		{TokenColon, ":"},
+		{TokenValue, "x"},
+		{TokenEscape, ";"},
+		{TokenValue, "y"},
+		{TokenQuote, `"`},
+	}
+
+	tokens, err := Tokenize(tag)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if got := kindTexts(tokens); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestTokenizeEscapes(t *testing.T) {
+	const tag = `gorm:"default:'ui\\path';foreignKey:Customer\"Id"`
+	expected := []kindText{
+		{TokenTagName, "gorm"},
+		{TokenColon, ":"},
+		{TokenQuote, `"`},
+		{TokenKey, "default"},
+		{TokenColon, ":"},
+		{TokenQuotedString, `ui\path`},
+		{TokenItemSeparator, ";"},
+		{TokenKey, "foreignKey"},
+		{TokenColon, ":"},
+		{TokenValue, "Customer"},
+		{TokenEscape, `"`},
+		{TokenValue, "Id"},
+		{TokenQuote, `"`},
+	}
+
+	tokens, err := Tokenize(tag)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if got := kindTexts(tokens); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSubtagLexer(t *testing.T) {
+	const subtag = ` label : Время создания ; readonly`
+	expected := []Token{
+		{TokenWhitespace, 0, 1, " "},
+		{TokenKey, 1, 6, "label"},
+		{TokenWhitespace, 6, 7, " "},
+		{TokenColon, 7, 8, ":"},
+		{TokenWhitespace, 8, 9, " "},
+		{TokenValue, 9, 36, "Время создания"},
+		{TokenWhitespace, 36, 37, " "},
+		{TokenItemSeparator, 37, 38, ";"},
+		{TokenWhitespace, 38, 39, " "},
+		{TokenValue, 39, 47, "readonly"},
+	}
+
+	tokens, err := NewSubtagLexer(subtag).All()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, tokens)
+	}
+}
+
+func TestTokensCoverInput(t *testing.T) {
+	tags := []string{
+		`gorm:"default:'something';not null"`,
+		`  json:"-,"   gorm:"check:,name <> 'jinzhu';index:idx,sort:desc"  `,
+		`ui:"label:Время создания; readonly; datatype:datetime;"`,
+		`gorm:"default:'a\'b';comment:\\;size:256\"" xml:"a\ b"`,
+		`gorm:"'a:b';;" valid:"x"`,
+	}
+
+	for _, tag := range tags {
+		tokens, err := Tokenize(tag)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tag, err)
+			continue
+		}
+
+		pos := 0
+		for _, token := range tokens {
+			if token.Start != pos || token.End <= token.Start {
+				t.Errorf("token %v of %q does not continue at offset %d", token, tag, pos)
+			}
+			pos = token.End
+		}
+		if pos != len(tag) {
+			t.Errorf("tokens of %q end at %d, expected %d", tag, pos, len(tag))
+		}
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`gorm:default:value'`, fmt.Sprintf(nonQuotedValueErr, "gorm")},
+		{`gorm:"default:'value"`, unclosedBacktickErr},
+		{`gorm:"default:'value`, unclosedBacktickErr},
+		{`json:"name" gorm`, fmt.Sprintf(valueLessTagErr, "gorm")},
+		{`gorm:x`, fmt.Sprintf(valueLessTagErr, "gorm")},
+	}
+
+	for _, test := range tests {
+		_, parseErr := Parse(test.tag)
+		if parseErr == nil || parseErr.Error() != test.expected {
+			t.Errorf("Parse(%q): expected error `%s`, got `%s`", test.tag, test.expected, parseErr)
+		}
+
+		_, lexErr := Tokenize(test.tag)
+		if lexErr == nil || lexErr.Error() != test.expected {
+			t.Errorf("Tokenize(%q): expected error `%s`, got `%s`", test.tag, test.expected, lexErr)
+		}
+	}
+}
+
+func TestLexerNextStopsAtEOF(t *testing.T) {
+	lexer := NewLexer(`json:"id"`)
+	count := 0
+	for {
+		_, err := lexer.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		count++
+	}
+
+	if count != 5 {
+		t.Errorf("expected 5 tokens, got %d", count)
+	}
+	if _, err := lexer.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last token, got %v", err)
+	}
+}
+
+func TestTokenKindString(t *testing.T) {
+	if TokenQuotedString.String() != "QuotedString" {
+		t.Errorf("unexpected name %s", TokenQuotedString)
+	}
+	if TokenKind(42).String() != "TokenKind(42)" {
+		t.Errorf("unexpected name %s", TokenKind(42))
+	}
+}
+
+// lexedItem is a subtag item rebuilt from the spans of its tokens.
+type lexedItem struct {
+	key    string
+	hasKey bool
+	value  string
+	args   []string
+}
+
+// lexedItems groups tokens into the items ParseSubtag splits content into,
+// skipping the empty ones it skips too.
+func lexedItems(content string, tokens []Token) []lexedItem {
+	var items []lexedItem
+
+	start := 0
+	item, valueStart, valueEnd, argStart := lexedItem{}, -1, -1, -1
+	flush := func(end int) {
+		if end > start {
+			if valueStart >= 0 {
+				item.value = content[valueStart:valueEnd]
+				item.args = append(item.args, content[argStart:valueEnd])
+			} else if item.hasKey {
+				item.args = append(item.args, "")
+			}
+			items = append(items, item)
+		}
+		item, valueStart, valueEnd, argStart = lexedItem{}, -1, -1, -1
+	}
+
+	for _, token := range tokens {
+		switch token.Kind {
+		case TokenItemSeparator:
+			flush(token.Start)
+			start = token.End
+		case TokenKey:
+			item.key = token.Text
+		case TokenColon:
+			item.hasKey = true
+		case TokenArgSeparator:
+			if valueStart < 0 {
+				valueStart, argStart = token.Start, token.Start
+			}
+			item.args = append(item.args, content[argStart:token.Start])
+			valueEnd, argStart = token.End, token.End
+		case TokenValue, TokenQuotedString, TokenEscape:
+			if valueStart < 0 {
+				valueStart, argStart = token.Start, token.Start
+			}
+			valueEnd = token.End
+		}
+	}
+	flush(len(content))
+	return items
+}
+
+func FuzzSubtagLexerMatchesParseSubtag(f *testing.F) {
+	seeds := []string{
+		``,
+		`not null`,
+		`column:id;size:32`,
+		` default : 'a; b' ; ;; index`,
+		`check:a,'b,c',\,d`,
+		`comment:it\'s;default:"x \"y\""`,
+		`'a:b';x:"'"`,
+		`path:C:\\dir\;tmp`,
+		`k:;:v`,
+		`a,b:c,d`,
+		`default:'a`,
+		`':',`,
+		"\u00a0x:\ty\u2003",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		tokens, lexErr := NewSubtagLexer(content).All()
+		tag, err := ParseSubtag(content, true, WithDuplicates(DuplicatesFirst))
+		// ParseSubtag rejects more than the lexer, such as params without a name
+		if err != nil {
+			return
+		} else if lexErr != nil {
+			t.Fatalf("%q: lexer error %v, ParseSubtag succeeds", content, lexErr)
+		}
+
+		var options, rawOptions []string
+		seen := map[string]bool{}
+		for _, item := range lexedItems(content, tokens) {
+			if !item.hasKey {
+				options = append(options, unescapeText(item.value, subtagEscapes))
+				rawOptions = append(rawOptions, item.value)
+				continue
+			}
+			if seen[item.key] {
+				continue
+			}
+			seen[item.key] = true
+
+			param := tag.GetParam(item.key)
+			if param == nil {
+				t.Fatalf("%q: lexed key %q, ParseSubtag has params %v", content, item.key, tag.GetParams())
+			}
+			if param.Raw != item.value {
+				t.Fatalf("%q: lexed value %q of %q, ParseSubtag gives %q", content, item.value, item.key, param.Raw)
+			}
+			args := make([]string, len(item.args))
+			for i, arg := range item.args {
+				args[i] = unescapeText(unquoteParamValue(arg), subtagEscapes)
+			}
+			if !reflect.DeepEqual(args, param.Args) {
+				t.Fatalf("%q: lexed args %q of %q, ParseSubtag gives %q", content, args, item.key, param.Args)
+			}
+		}
+
+		if len(seen) != len(tag.GetParams()) {
+			t.Fatalf("%q: lexed keys %v, ParseSubtag has params %v", content, seen, tag.GetParams())
+		}
+		if !reflect.DeepEqual(options, nonNil(tag.GetOptions())) || !reflect.DeepEqual(rawOptions, nonNil(tag.GetRawOptions())) {
+			t.Fatalf("%q: lexed options %q %q, ParseSubtag gives %q %q", content, options, rawOptions, tag.GetOptions(), tag.GetRawOptions())
+		}
+	})
+}
+
+func FuzzLexerMatchesParse(f *testing.F) {
+	seeds := []string{
+		``,
+		`json:"id"`,
+		`  json:"id,omitempty"   gorm:"default:'a b';not null" `,
+		`gorm:"comment:a\ b" json:"-"`,
+		`gorm:"default:\"x\""`,
+		`a\ b:"c"`,
+		`json:"a" json:"b"`,
+		`json:id`,
+		`json`,
+		`json:"a`,
+		`gorm:"default:'a"`,
+		`:""`,
+		`:":" `,
+		`\ a\ :""`,
+		`\ :""`,
+		`:""\ `,
+		`a:"\\"\ `,
+		`"\\ ":""`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		tokens, lexErr := Tokenize(content)
+		storage, err := Parse(content, WithDuplicates(DuplicatesFirst))
+		if lexErr != nil && err == nil {
+			t.Fatalf("%q: lexer error %v, Parse succeeds", content, lexErr)
+		} else if err != nil {
+			return
+		}
+
+		var names []string
+		for _, token := range tokens {
+			if token.Kind == TokenTagName && token.Text != "" && !slices.Contains(names, token.Text) {
+				names = append(names, token.Text)
+			}
+		}
+		// The lexer emits no empty tokens, so empty names are left out
+		expected := slices.DeleteFunc(slices.Clone(storage.Names()), func(name string) bool { return name == "" })
+		if !reflect.DeepEqual(names, nonNil(expected)) {
+			t.Fatalf("%q: lexed names %q, Parse gives %q", content, names, storage.Names())
+		}
+	})
+}
+
+func nonNil(items []string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
@@ -0,0 +1,132 @@
+package fogg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Limits bounds the work done parsing untrusted tags. Zero fields are not
+// limited.
+type Limits struct {
+	// MaxLength is the maximum length of the parsed content in bytes
+	MaxLength int
+	// MaxTags is the maximum number of tags parsed by Parse
+	MaxTags int
+	// MaxParams is the maximum number of params of a tag, repeated ones included
+	MaxParams int
+	// MaxArgs is the maximum number of args of a param
+	MaxArgs int
+	// MaxQuoteDepth is the maximum number of quotes nested in a tag
+	MaxQuoteDepth int
+}
+
+// ErrLimitExceeded is matched by errors.Is for every LimitError.
+var ErrLimitExceeded = errors.New("parse limit exceeded")
+
+// LimitError is returned when parsed content exceeds one of Limits, named by
+// Limit.
+type LimitError struct {
+	Limit string
+	Max   int
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf(limitExceededErr, err.Limit, err.Max)
+}
+
+func (err *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// WithLimits makes Parse and ParseSubtag fail with a LimitError on content
+// exceeding limits.
+func WithLimits(limits Limits) ParseOption {
+	return func(config *parseConfig) {
+		config.limits = limits
+	}
+}
+
+func checkLimit(limit string, max, count int) error {
+	if max > 0 && count > max {
+		return &LimitError{Limit: limit, Max: max}
+	}
+	return nil
+}
+
+// checkParams checks the params of a tag parsed by a dialect.
+func (limits *Limits) checkParams(tag *Tag) error {
+	count := 0
+	for name := range tag.params {
+		for _, param := range tag.GetParamAll(name) {
+			count++
+			if err := checkLimit("MaxArgs", limits.MaxArgs, len(param.Args)); err != nil {
+				return err
+			}
+		}
+	}
+	return checkLimit("MaxParams", limits.MaxParams, count)
+}
+
+// checkQuoteDepth scans content for quotes as scanTagItems does, failing as
+// soon as they are nested deeper than max.
+func checkQuoteDepth(content string, quotes byteSet, max int) error {
+	if max <= 0 {
+		return nil
+	}
+
+	var quotesStack []byte
+	for pos := 0; pos < len(content); pos++ {
+		char := content[pos]
+		switch {
+		case char == escapeBackslash && pos+1 < len(content):
+			if next := content[pos+1]; next == escapeBackslash || quotes[next] {
+				pos++
+			}
+		case quotes[char]:
+			if depth := len(quotesStack); depth > 0 && quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
+			} else if quotesStack = append(quotesStack, char); len(quotesStack) > max {
+				return &LimitError{Limit: "MaxQuoteDepth", Max: max}
+			}
+		}
+	}
+	return nil
+}
+
+// WithContext makes Parse and ParseSubtag stop with the error of ctx once it is
+// done. ctx is checked before every tag and every item of a tag.
+func WithContext(ctx context.Context) ParseOption {
+	return func(config *parseConfig) {
+		config.ctx = ctx
+	}
+}
+
+// canceled returns the error of the context set by WithContext.
+func (config *parseConfig) canceled() error {
+	if config.ctx == nil {
+		return nil
+	}
+	return config.ctx.Err()
+}
+
+// ParseAll parses every content of contents with options, and stops with the
+// error of ctx once it is done, within a content too as WithContext does. On
+// error it returns the storages parsed before the failing content.
+func ParseAll(ctx context.Context, contents []string, options ...ParseOption) ([]Storage, error) {
+	options = append(options[:len(options):len(options)], WithContext(ctx))
+
+	storages := make([]Storage, 0, len(contents))
+	for _, content := range contents {
+		if err := ctx.Err(); err != nil {
+			return storages, err
+		}
+
+		storage, err := Parse(content, options...)
+		if err != nil {
+			return storages, err
+		}
+		storages = append(storages, storage)
+	}
+	return storages, nil
+}
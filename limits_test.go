@@ -0,0 +1,107 @@
+package fogg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		tag      string
+		limits   Limits
+		expected string
+	}{
+		{`json:"id"`, Limits{MaxLength: 8}, `MaxLength limit of 8 exceeded`},
+		{`json:"id" xml:"id" gorm:"id"`, Limits{MaxTags: 2}, `MaxTags limit of 2 exceeded`},
+		{`gorm:"a:1;b:2;c:3"`, Limits{MaxParams: 2}, `MaxParams limit of 2 exceeded`},
+		{`gorm:"a:1;a:2"`, Limits{MaxParams: 1}, `MaxParams limit of 1 exceeded`},
+		{`gorm:"index:a,b,c"`, Limits{MaxArgs: 2}, `MaxArgs limit of 2 exceeded`},
+		{`protobuf:"bytes,1,opt,name=id"`, Limits{MaxParams: 0, MaxArgs: 0}, ``},
+		{`gorm:"a:1;b:2" json:"id"`, Limits{MaxLength: 24, MaxTags: 2, MaxParams: 2, MaxArgs: 1, MaxQuoteDepth: 1}, ``},
+		{`gorm:"default:'a\"b\"'"`, Limits{MaxQuoteDepth: 1}, ``},
+		{`gorm:"default:'a\\'b'"`, Limits{MaxQuoteDepth: 1}, ``},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.tag, WithLimits(test.limits), WithDuplicates(DuplicatesCollect), WithGoEscapes())
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %s", test.tag, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%q: got error %v, expected %q", test.tag, err, test.expected)
+		}
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%q: expected error %v to be ErrLimitExceeded", test.tag, err)
+		}
+	}
+}
+
+func TestParseSubtagLimits(t *testing.T) {
+	_, err := ParseSubtag(strings.Repeat(`'"`, 32), true, WithLimits(Limits{MaxQuoteDepth: 8}))
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "MaxQuoteDepth" || limitErr.Max != 8 {
+		t.Errorf("got error %v, expected MaxQuoteDepth to be exceeded", err)
+	}
+
+	if _, err := ParseSubtag("a;b", true, WithLimits(Limits{MaxLength: 2})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got error %v, expected MaxLength to be exceeded", err)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	contents := []string{`json:"a"`, `json:"b"`, `json:"c" json:"d"`, `json:"e"`}
+
+	storages, err := ParseAll(context.Background(), contents[:2])
+	if err != nil || len(storages) != 2 || storages[1].GetTag("json").GetContent() != "b" {
+		t.Errorf("got %v, %v, expected two storages", storages, err)
+	}
+
+	storages, err = ParseAll(context.Background(), contents)
+	if err == nil || err.Error() != `duplicated tags with name "json"` || len(storages) != 2 {
+		t.Errorf("got %d storages and error %v, expected 2 storages and a duplicated tags error", len(storages), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if storages, err = ParseAll(ctx, contents); !errors.Is(err, context.Canceled) || len(storages) != 0 {
+		t.Errorf("got %d storages and error %v, expected parsing to be canceled", len(storages), err)
+	}
+}
+
+// cancelAfter is a context canceled once its error is checked calls times.
+type cancelAfter struct {
+	context.Context
+	calls int
+}
+
+func (ctx *cancelAfter) Err() error {
+	if ctx.calls--; ctx.calls < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseAllCancelWithinContent(t *testing.T) {
+	huge := `gorm:"` + strings.Repeat("index;", 10000) + `"`
+
+	ctx := &cancelAfter{Context: context.Background(), calls: 100}
+	if _, err := ParseAll(ctx, []string{huge}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, expected parsing to be canceled", err)
+	}
+	if ctx.calls > 0 {
+		t.Errorf("parsing stopped before the context was canceled")
+	}
+
+	ctx = &cancelAfter{Context: context.Background(), calls: 1}
+	if _, err := Parse(`json:"a" xml:"b"`, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, expected parsing to be canceled", err)
+	}
+	if _, err := Parse(huge, WithContext(context.Background())); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,176 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MergePolicy decides which side of a merge wins when both define a tag, or a
+// param within a tag.
+type MergePolicy int
+
+const (
+	// MergeError fails on conflicts, as Parse does on repeated tags and params
+	MergeError MergePolicy = iota
+	// MergeFirstWins keeps what is already defined
+	MergeFirstWins
+	// MergeLastWins replaces what is already defined
+	MergeLastWins
+	// MergeUnion merges tags defined on both sides with the union of their
+	// options, their values and params as MergeLastWins does
+	MergeUnion
+)
+
+// OverrideKind is what a MergeOverride dropped.
+type OverrideKind int
+
+const (
+	// OverrideTag is a whole tag, Kept and Dropped are the contents of the tags
+	OverrideTag OverrideKind = iota
+	// OverrideValue is the value of a tag
+	OverrideValue
+	// OverrideParam is the param named by Param
+	OverrideParam
+	// OverrideOption is an option of the dropped side, Kept is empty
+	OverrideOption
+)
+
+// MergeOverride is a tag, value, param or option dropped by a merge.
+type MergeOverride struct {
+	Kind    OverrideKind
+	Tag     string
+	Param   string
+	Kept    string
+	Dropped string
+}
+
+// Merge adds the tags of other to storage, resolving tags defined on both sides
+// with policy. storage is left unchanged on error.
+func (storage *Storage) Merge(other Storage, policy MergePolicy) ([]MergeOverride, error) {
+	merged := Storage{
+		tags:      make(map[string]Tag, len(storage.tags)+len(other.tags)),
+		names:     slices.Clone(storage.names),
+		goEscapes: storage.goEscapes,
+		dialects:  storage.dialects,
+	}
+	maps.Copy(merged.tags, storage.tags)
+
+	var overrides []MergeOverride
+	for _, name := range other.names {
+		tag, otherTag := merged.tags[name], other.tags[name]
+		if _, exists := merged.tags[name]; !exists {
+			merged.tags[name] = otherTag
+			merged.names = append(merged.names, name)
+			continue
+		}
+
+		switch policy {
+		case MergeError:
+			return nil, errors.New(fmt.Sprintf(duplicatedTagsErr, name))
+		case MergeFirstWins:
+			overrides = append(overrides, MergeOverride{Tag: name, Kept: tag.content, Dropped: otherTag.content})
+		case MergeLastWins:
+			overrides = append(overrides, MergeOverride{Tag: name, Kept: otherTag.content, Dropped: tag.content})
+			merged.tags[name] = otherTag
+		default:
+			tagOverrides, err := tag.Merge(otherTag, policy)
+			if err != nil {
+				return nil, err
+			}
+			overrides = append(overrides, tagOverrides...)
+			merged.tags[name] = tag
+		}
+	}
+
+	*storage = merged
+	return overrides, nil
+}
+
+// Merge adds the params and options of other to tag, resolving values and
+// params defined on both sides with policy. MergeError and MergeUnion keep the
+// options of both sides, the other policies the options of the winning side.
+// tag is left unchanged on error.
+func (tag *Tag) Merge(other Tag, policy MergePolicy) ([]MergeOverride, error) {
+	kept, dropped := tag, &other
+	if policy == MergeLastWins {
+		kept, dropped = dropped, kept
+	}
+
+	merged := Tag{
+		name:       tag.name,
+		params:     make(map[string]TagParam, len(tag.params)+len(other.params)),
+		options:    slices.Clone(kept.options),
+		rawOptions: slices.Clone(kept.rawOptions),
+		normalize:  tag.normalize,
+		dialect:    tag.dialect || other.dialect,
+	}
+	if merged.name == "" {
+		merged.name = other.name
+	}
+
+	var overrides []MergeOverride
+	if hasPositionalValue(&merged) {
+		merged.value = tag.value
+		switch {
+		case other.value == "" || other.value == tag.value:
+		case tag.value == "":
+			merged.value = other.value
+		case policy == MergeError:
+			return nil, errors.New(fmt.Sprintf(mergeValueConflictErr, tag.value, other.value, merged.name))
+		case policy == MergeFirstWins:
+			overrides = append(overrides, MergeOverride{Kind: OverrideValue, Tag: merged.name, Kept: tag.value, Dropped: other.value})
+		default:
+			overrides = append(overrides, MergeOverride{Kind: OverrideValue, Tag: merged.name, Kept: other.value, Dropped: tag.value})
+			merged.value = other.value
+		}
+	}
+
+	for i, option := range dropped.options {
+		if merged.HasOption(option) {
+			continue
+		}
+		if policy == MergeError || policy == MergeUnion {
+			merged.options = append(merged.options, option)
+			merged.rawOptions = append(merged.rawOptions, dropped.rawOptions[i])
+		} else {
+			overrides = append(overrides, MergeOverride{Kind: OverrideOption, Tag: merged.name, Dropped: dropped.rawOptions[i]})
+		}
+	}
+	if !hasPositionalValue(&merged) && len(merged.options) > 0 {
+		merged.value = merged.options[0]
+	}
+
+	maps.Copy(merged.params, tag.params)
+	for name, params := range tag.duplicates {
+		merged.addDuplicates(name, params...)
+	}
+	for _, name := range sortedParamNames(&other) {
+		otherParam := other.params[name]
+		key, exists := tag.paramKey(name)
+		if !exists {
+			merged.params[name] = otherParam
+			merged.addDuplicates(name, other.duplicates[name]...)
+			continue
+		}
+
+		switch policy {
+		case MergeError:
+			return nil, errors.New(fmt.Sprintf(duplicatedParamErr, name))
+		case MergeFirstWins:
+			overrides = append(overrides, MergeOverride{Kind: OverrideParam, Tag: merged.name, Param: key, Kept: tag.params[key].Value, Dropped: otherParam.Value})
+		default:
+			overrides = append(overrides, MergeOverride{Kind: OverrideParam, Tag: merged.name, Param: name, Kept: otherParam.Value, Dropped: tag.params[key].Value})
+			delete(merged.params, key)
+			delete(merged.duplicates, key)
+			merged.params[name] = otherParam
+			merged.addDuplicates(name, other.duplicates[name]...)
+		}
+	}
+	merged.index()
+
+	merged.content = formatDialectSubtag(&merged)
+	*tag = merged
+	return overrides, nil
+}
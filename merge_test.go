@@ -0,0 +1,220 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStorageMerge(t *testing.T) {
+	const (
+		base  = `json:"id" gorm:"column:id;not null;size:32"`
+		other = `gorm:"primaryKey;size:64" validate:"required"`
+	)
+
+	tests := []struct {
+		policy    MergePolicy
+		expected  string
+		overrides []MergeOverride
+	}{
+		{
+			MergeFirstWins,
+			`json:"id" gorm:"column:id;not null;size:32" validate:"required"`,
+			[]MergeOverride{{Tag: "gorm", Kept: "column:id;not null;size:32", Dropped: "primaryKey;size:64"}},
+		},
+		{
+			MergeLastWins,
+			`json:"id" gorm:"primaryKey;size:64" validate:"required"`,
+			[]MergeOverride{{Tag: "gorm", Kept: "primaryKey;size:64", Dropped: "column:id;not null;size:32"}},
+		},
+		{
+			MergeUnion,
+			`json:"id" gorm:"not null;primaryKey;column:id;size:64" validate:"required"`,
+			[]MergeOverride{{Kind: OverrideParam, Tag: "gorm", Param: "size", Kept: "64", Dropped: "32"}},
+		},
+	}
+
+	for _, test := range tests {
+		storage, otherStorage := mustParse(t, base), mustParse(t, other)
+		overrides, err := storage.Merge(otherStorage, test.policy)
+		if err != nil {
+			t.Errorf("policy %d: unexpected error: %s", test.policy, err)
+			continue
+		}
+		if tag := storage.String(); tag != test.expected {
+			t.Errorf("policy %d: got %q, expected %q", test.policy, tag, test.expected)
+		}
+		if !reflect.DeepEqual(overrides, test.overrides) {
+			t.Errorf("policy %d: got overrides %+v, expected %+v", test.policy, overrides, test.overrides)
+		}
+	}
+
+	storage := mustParse(t, base)
+	if _, err := storage.Merge(mustParse(t, other), MergeError); err == nil || err.Error() != `duplicated tags with name "gorm"` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if tag := storage.String(); tag != base {
+		t.Errorf("failed merge changed the storage to %q", tag)
+	}
+
+	overrides, err := storage.Merge(mustParse(t, `xml:"id,attr"`), MergeError)
+	if err != nil || overrides != nil || storage.String() != base+` xml:"id,attr"` {
+		t.Errorf("got %q, %+v, %v", storage.String(), overrides, err)
+	}
+}
+
+func TestTagMerge(t *testing.T) {
+	tests := []struct {
+		tag      string
+		other    string
+		policy   MergePolicy
+		expected string
+		value    string
+	}{
+		{`gorm:"index;default:'a;b'"`, `gorm:"unique;type:text"`, MergeError, `index;unique;default:'a;b';type:text`, "index"},
+		{`gorm:"column:a"`, `gorm:"not null"`, MergeError, `not null;column:a`, "not null"},
+		{`gorm:"index;column:a"`, `gorm:"unique;column:b"`, MergeFirstWins, `index;column:a`, "index"},
+		{`gorm:"index;column:a"`, `gorm:"unique;column:b"`, MergeLastWins, `unique;column:b`, "unique"},
+		{`gorm:"index;column:a"`, `gorm:"index;unique;column:b"`, MergeUnion, `index;unique;column:b`, "index"},
+		{`json:"name"`, `json:",omitempty"`, MergeUnion, `name,omitempty`, "name"},
+		{`json:"name,omitempty"`, `json:"full_name,string"`, MergeLastWins, `full_name,string`, "full_name"},
+		{`validate:"required"`, `validate:"min=1,max=8"`, MergeUnion, `required,min=1,max=8`, "required"},
+		{`protobuf:"bytes,1,opt,name=foo"`, `protobuf:"bytes,1,opt,json=fooBar,def=a,b"`, MergeUnion, `bytes,1,opt,json=fooBar,name=foo,def=a,b`, "bytes"},
+	}
+
+	for _, test := range tests {
+		storage := mustParse(t, test.tag, WithDialects())
+		name := storage.Names()[0]
+		tag := *storage.GetTag(name)
+		other := mustParse(t, test.other, WithDialects())
+
+		if _, err := tag.Merge(*other.GetTag(name), test.policy); err != nil {
+			t.Errorf("%s + %s: unexpected error: %s", test.tag, test.other, err)
+			continue
+		}
+		if tag.GetContent() != test.expected || tag.GetValue() != test.value {
+			t.Errorf("%s + %s: got %q with value %q, expected %q with value %q", test.tag, test.other, tag.GetContent(), tag.GetValue(), test.expected, test.value)
+		}
+
+		reparsed := mustParse(t, name+`:"`+tag.GetContent()+`"`, WithDialects())
+		if !reflect.DeepEqual(reparsed.GetTag(name).GetParams(), tag.GetParams()) {
+			t.Errorf("%s + %s: got params %+v after parsing the merged content, expected %+v", test.tag, test.other, reparsed.GetTag(name).GetParams(), tag.GetParams())
+		}
+	}
+
+	storage, other := mustParse(t, `gorm:"column:a"`), mustParse(t, `gorm:"column:b"`)
+	tag := *storage.GetTag("gorm")
+	if _, err := tag.Merge(*other.GetTag("gorm"), MergeError); err == nil || err.Error() != `duplicated param "column" in tag` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if tag.GetParam("column").Value != "a" || tag.GetContent() != "column:a" {
+		t.Errorf("failed merge changed the tag to %+v", tag)
+	}
+}
+
+func TestTagMergePolicies(t *testing.T) {
+	tests := []struct {
+		tag       string
+		other     string
+		policy    MergePolicy
+		expected  string
+		overrides []MergeOverride
+		err       string
+	}{
+		{
+			`json:"id,omitempty"`, `json:"name,string"`, MergeError,
+			``, nil, `conflicting values "id" and "name" of tag "json"`,
+		},
+		{
+			`json:"id,omitempty"`, `json:"name,string"`, MergeFirstWins,
+			`id,omitempty`,
+			[]MergeOverride{
+				{Kind: OverrideValue, Tag: "json", Kept: "id", Dropped: "name"},
+				{Kind: OverrideOption, Tag: "json", Dropped: "string"},
+			},
+			``,
+		},
+		{
+			`json:"id,omitempty"`, `json:"name,string"`, MergeLastWins,
+			`name,string`,
+			[]MergeOverride{
+				{Kind: OverrideValue, Tag: "json", Kept: "name", Dropped: "id"},
+				{Kind: OverrideOption, Tag: "json", Dropped: "omitempty"},
+			},
+			``,
+		},
+		{
+			`json:"id,omitempty"`, `json:"name,string"`, MergeUnion,
+			`name,omitempty,string`,
+			[]MergeOverride{{Kind: OverrideValue, Tag: "json", Kept: "name", Dropped: "id"}},
+			``,
+		},
+		{
+			`json:",omitempty"`, `json:"name,omitempty"`, MergeError,
+			`name,omitempty`, nil, ``,
+		},
+		{
+			`gorm:"index;size:32"`, `gorm:"unique;size:64"`, MergeError,
+			``, nil, `duplicated param "size" in tag`,
+		},
+		{
+			`gorm:"index;size:32"`, `gorm:"unique;size:64"`, MergeFirstWins,
+			`index;size:32`,
+			[]MergeOverride{
+				{Kind: OverrideOption, Tag: "gorm", Dropped: "unique"},
+				{Kind: OverrideParam, Tag: "gorm", Param: "size", Kept: "32", Dropped: "64"},
+			},
+			``,
+		},
+		{
+			`gorm:"index;size:32"`, `gorm:"unique;size:64"`, MergeLastWins,
+			`unique;size:64`,
+			[]MergeOverride{
+				{Kind: OverrideOption, Tag: "gorm", Dropped: "index"},
+				{Kind: OverrideParam, Tag: "gorm", Param: "size", Kept: "64", Dropped: "32"},
+			},
+			``,
+		},
+		{
+			`gorm:"index;size:32"`, `gorm:"unique;size:64"`, MergeUnion,
+			`index;unique;size:64`,
+			[]MergeOverride{{Kind: OverrideParam, Tag: "gorm", Param: "size", Kept: "64", Dropped: "32"}},
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		storage, other := mustParse(t, test.tag, WithDialects()), mustParse(t, test.other, WithDialects())
+		name := storage.Names()[0]
+		tag := *storage.GetTag(name)
+
+		overrides, err := tag.Merge(*other.GetTag(name), test.policy)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s + %s, policy %d: got error %v, expected %q", test.tag, test.other, test.policy, err, test.err)
+			}
+			if tag.GetContent() != storage.GetTag(name).GetContent() {
+				t.Errorf("%s + %s, policy %d: failed merge changed the tag to %q", test.tag, test.other, test.policy, tag.GetContent())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s + %s, policy %d: unexpected error: %s", test.tag, test.other, test.policy, err)
+			continue
+		}
+		if tag.GetContent() != test.expected {
+			t.Errorf("%s + %s, policy %d: got %q, expected %q", test.tag, test.other, test.policy, tag.GetContent(), test.expected)
+		}
+		if !reflect.DeepEqual(overrides, test.overrides) {
+			t.Errorf("%s + %s, policy %d: got overrides %+v, expected %+v", test.tag, test.other, test.policy, overrides, test.overrides)
+		}
+	}
+}
+
+func mustParse(t *testing.T, tag string, options ...ParseOption) Storage {
+	t.Helper()
+	storage, err := Parse(tag, options...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return storage
+}
@@ -0,0 +1,126 @@
+package fogg
+
+import (
+	"context"
+	"strings"
+)
+
+// Duplicates decides what parsing does with tags and params written more than
+// once.
+type Duplicates int
+
+const (
+	// DuplicatesError fails on repeated tags and params
+	DuplicatesError Duplicates = iota
+	// DuplicatesFirst keeps the first occurrence
+	DuplicatesFirst
+	// DuplicatesLast keeps the last occurrence
+	DuplicatesLast
+	// DuplicatesCollect keeps every occurrence: repeated tags are merged into
+	// the first one and repeated params are returned by Tag.GetParamAll
+	DuplicatesCollect
+)
+
+// Normalizer maps the equivalent spellings of a param key or an option to the
+// same string.
+type Normalizer func(key string) string
+
+// ParseOption configures Parse and ParseSubtag.
+type ParseOption func(config *parseConfig)
+
+type parseConfig struct {
+	duplicates Duplicates
+	normalize  Normalizer
+	syntax     syntax
+	goEscapes  bool
+	limits     Limits
+	ctx        context.Context
+	dialects   bool
+}
+
+// syntax is the grammar Parse reads whole struct tags with.
+type syntax int
+
+const (
+	syntaxDefault syntax = iota
+	syntaxLenient
+	syntaxStrict
+)
+
+func newParseConfig(options []ParseOption) *parseConfig {
+	config := &parseConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// WithDuplicates sets how repeated tags and params are handled, DuplicatesError
+// by default.
+func WithDuplicates(duplicates Duplicates) ParseOption {
+	return func(config *parseConfig) {
+		config.duplicates = duplicates
+	}
+}
+
+// WithNormalizer makes tags match param keys and options after normalizing them
+// with normalizers, in order. Tags keep the spellings they were written with.
+// Params with keys normalized alike are repeated params.
+func WithNormalizer(normalizers ...Normalizer) ParseOption {
+	return func(config *parseConfig) {
+		config.normalize = func(key string) string {
+			for _, normalize := range normalizers {
+				key = normalize(key)
+			}
+			return key
+		}
+	}
+}
+
+// FoldCase normalizes keys to lower case, as GORM ignores their case.
+func FoldCase(key string) string {
+	return strings.ToLower(key)
+}
+
+// CollapseSpaces trims keys and replaces their runs of whitespace by a single
+// space, so that `not  null` matches `not null`.
+func CollapseSpaces(key string) string {
+	return strings.Join(strings.Fields(key), " ")
+}
+
+// WithLenient makes Parse accept tags separated by any whitespace, e.g. tabs
+// or newlines written by generators, and values without quotation marks. Every
+// deviation is reported by Storage.Warnings.
+func WithLenient() ParseOption {
+	return func(config *parseConfig) {
+		config.syntax = syntaxLenient
+	}
+}
+
+// WithDialects makes Parse read json, xml, validate and protobuf tags in their
+// own styles rather than in the GORM one, e.g. the value of a json tag is its
+// name and the items after it are options.
+func WithDialects() ParseOption {
+	return func(config *parseConfig) {
+		config.dialects = true
+	}
+}
+
+// WithGoEscapes makes Parse decode tag values as Go string literals, as
+// reflect.StructTag.Lookup does, e.g. `\n`, `\u00e9` and `\x41`. Storage.String
+// then writes values back as Go string literals.
+func WithGoEscapes() ParseOption {
+	return func(config *parseConfig) {
+		config.goEscapes = true
+	}
+}
+
+// WithStrict makes Parse read tags as reflect.StructTag does, with the checks
+// of go vet. It implies WithGoEscapes and DuplicatesFirst.
+func WithStrict() ParseOption {
+	return func(config *parseConfig) {
+		config.syntax = syntaxStrict
+		config.goEscapes = true
+		config.duplicates = DuplicatesFirst
+	}
+}
@@ -0,0 +1,245 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDuplicatedParams(t *testing.T) {
+	const tag = `gorm:"index:idx_name;not null;index:idx_name_age,unique;index:,sort:desc"`
+
+	tests := []struct {
+		duplicates Duplicates
+		expected   []string
+	}{
+		{DuplicatesFirst, []string{"idx_name"}},
+		{DuplicatesLast, []string{",sort:desc"}},
+		{DuplicatesCollect, []string{"idx_name", "idx_name_age,unique", ",sort:desc"}},
+	}
+
+	for _, test := range tests {
+		storage, err := Parse(tag, WithDuplicates(test.duplicates))
+		if err != nil {
+			t.Errorf("duplicates %d: unexpected error: %s", test.duplicates, err)
+			continue
+		}
+
+		gorm := storage.GetTag("gorm")
+		var values []string
+		for _, param := range gorm.GetParamAll("index") {
+			values = append(values, param.Value)
+		}
+		if !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("duplicates %d: got %v, expected %v", test.duplicates, values, test.expected)
+		}
+		if param := gorm.GetParam("index"); param.Value != test.expected[0] {
+			t.Errorf("duplicates %d: got param %q, expected %q", test.duplicates, param.Value, test.expected[0])
+		}
+	}
+
+	if _, err := Parse(tag); err == nil || err.Error() != `duplicated param "index" in tag` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := ParseSubtag("a:1;a:2", true, WithDuplicates(DuplicatesError)); err == nil || err.Error() != `duplicated param "a" in tag` {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	subtag, err := ParseSubtag("a:1;a:2", true, WithDuplicates(DuplicatesCollect))
+	if err != nil || len(subtag.GetParamAll("a")) != 2 || subtag.GetParamAll("b") != nil {
+		t.Errorf("got %+v, %v", subtag.GetParamAll("a"), err)
+	}
+}
+
+func TestParseDuplicatedTags(t *testing.T) {
+	const tag = `binding:"required;max:8" json:"name" binding:"email;max:16;min:1"`
+
+	tests := []struct {
+		duplicates Duplicates
+		expected   string
+	}{
+		{DuplicatesFirst, `binding:"required;max:8" json:"name"`},
+		{DuplicatesLast, `binding:"email;max:16;min:1" json:"name"`},
+		{DuplicatesCollect, `binding:"required;email;max:8;max:16;min:1" json:"name"`},
+	}
+
+	for _, test := range tests {
+		storage, err := Parse(tag, WithDuplicates(test.duplicates))
+		if err != nil {
+			t.Errorf("duplicates %d: unexpected error: %s", test.duplicates, err)
+			continue
+		}
+		if written := storage.String(); written != test.expected {
+			t.Errorf("duplicates %d: got %q, expected %q", test.duplicates, written, test.expected)
+		}
+	}
+
+	storage, err := Parse(tag, WithDuplicates(DuplicatesCollect))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	binding := storage.GetTag("binding")
+	if len(binding.GetParamAll("max")) != 2 || binding.GetValue() != "required" {
+		t.Errorf("unexpected binding tag %+v", binding)
+	}
+
+	other, _ := Parse(`binding:"max:32"`)
+	overrides, err := storage.Merge(other, MergeUnion)
+	if err != nil || len(overrides) != 1 || len(storage.GetTag("binding").GetParamAll("max")) != 1 {
+		t.Errorf("got %+v, %+v, %v", storage.GetTag("binding").GetParamAll("max"), overrides, err)
+	}
+}
+
+func TestParseNormalizer(t *testing.T) {
+	storage, err := Parse(`gorm:"PRIMARYKEY;NOT  NULL;Column:id;embeddedPrefix:a_"`, WithNormalizer(FoldCase, CollapseSpaces))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gorm := storage.GetTag("gorm")
+
+	for _, option := range []string{"primaryKey", "primarykey", "PRIMARYKEY", "not null", "NOT NULL", " Not  Null "} {
+		if !gorm.HasOption(option) {
+			t.Errorf("expected option %q", option)
+		}
+	}
+	if gorm.HasOption("unique") {
+		t.Errorf("unexpected option unique")
+	}
+
+	for _, key := range []string{"column", "COLUMN", "Column"} {
+		if !gorm.HasParam(key) || gorm.GetParam(key).Value != "id" || gorm.GetParamOr(key, "") != "id" || len(gorm.GetParamAll(key)) != 1 {
+			t.Errorf("expected param %q", key)
+		}
+	}
+	if gorm.GetParamOr("EMBEDDEDPREFIX", "") != "a_" {
+		t.Errorf("expected param EMBEDDEDPREFIX")
+	}
+
+	if param := gorm.GetParam("column"); param.Name != "Column" {
+		t.Errorf("got param name %q, expected the original spelling", param.Name)
+	}
+	if !reflect.DeepEqual(gorm.GetOptions(), []string{"PRIMARYKEY", "NOT  NULL"}) {
+		t.Errorf("got options %v, expected the original spellings", gorm.GetOptions())
+	}
+	if _, exists := gorm.GetParams()["Column"]; !exists {
+		t.Errorf("expected params to be keyed by the original spelling")
+	}
+
+	exact, _ := Parse(`gorm:"PRIMARYKEY;Column:id"`)
+	if exact.GetTag("gorm").HasOption("primaryKey") || exact.GetTag("gorm").HasParam("column") {
+		t.Errorf("expected exact matching without a normalizer")
+	}
+
+	json, _ := Parse(`json:"id,OmitEmpty"`, WithNormalizer(FoldCase), WithDialects())
+	if !json.GetTag("json").HasOption("omitempty") {
+		t.Errorf("expected normalized options in dialect tags")
+	}
+}
+
+func TestParseNormalizerDuplicates(t *testing.T) {
+	const tag = `gorm:"column:a;COLUMN:b"`
+
+	if _, err := Parse(tag, WithNormalizer(FoldCase)); err == nil || err.Error() != `duplicated param "COLUMN" in tag` {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	storage, err := Parse(tag, WithNormalizer(FoldCase), WithDuplicates(DuplicatesLast))
+	if err != nil || storage.GetTag("gorm").GetParamOr("Column", "") != "b" || len(storage.GetTag("gorm").GetParams()) != 1 {
+		t.Errorf("got %+v, %v", storage.GetTag("gorm"), err)
+	}
+
+	storage, err = Parse(tag, WithNormalizer(FoldCase), WithDuplicates(DuplicatesCollect))
+	if err != nil || len(storage.GetTag("gorm").GetParamAll("column")) != 2 {
+		t.Errorf("got %+v, %v", storage.GetTag("gorm"), err)
+	}
+
+	storage, _ = Parse(`gorm:"Size:8"`, WithNormalizer(FoldCase))
+	other, _ := Parse(`gorm:"size:16"`)
+	overrides, err := storage.Merge(other, MergeLastWins)
+	expected := []MergeOverride{{Tag: "gorm", Kept: "size:16", Dropped: "Size:8"}}
+	if err != nil || !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("got %+v, %v", overrides, err)
+	}
+
+	storage, _ = Parse(`gorm:"Size:8"`, WithNormalizer(FoldCase))
+	overrides, err = storage.Merge(other, MergeUnion)
+	expected = []MergeOverride{{Kind: OverrideParam, Tag: "gorm", Param: "size", Kept: "16", Dropped: "8"}}
+	if err != nil || !reflect.DeepEqual(overrides, expected) || storage.String() != `gorm:"size:16"` {
+		t.Errorf("got %q, %+v, %v", storage.String(), overrides, err)
+	}
+}
+
+func TestParseGoEscapes(t *testing.T) {
+	tests := []struct {
+		tag     string
+		written string
+	}{
+		{`json:"id"`, `json:"id"`},
+		{`env:"A\tB\n" note:"\u00e9\x41\101"`, `env:"A\tB\n" note:"éAA"`},
+		{`gorm:"default:\"a b\";not null"`, `gorm:"default:\"a b\";not null"`},
+		{`note:"\\d+\x00"`, `note:"\\d+\x00"`},
+		{`b:"q\"r" gorm:"default:'a\"b';size:\"8\""`, `b:"q\"r" gorm:"default:'a\"b';size:\"8\""`},
+		{`a:"x\\ y"  b:"\\;"`, `a:"x\\ y" b:"\\;"`},
+	}
+
+	for _, test := range tests {
+		storage, err := Parse(test.tag, WithGoEscapes())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.tag, err)
+			continue
+		}
+		for _, name := range storage.Names() {
+			expected, _ := reflect.StructTag(test.tag).Lookup(name)
+			if content := storage.GetTag(name).content; content != expected {
+				t.Errorf("%q: got %q for %q, expected %q", test.tag, content, name, expected)
+			}
+		}
+		if written := storage.String(); written != test.written {
+			t.Errorf("%q: written as %q, expected %q", test.tag, written, test.written)
+		}
+	}
+
+	storage, err := Parse(`b:"q\"r" gorm:"default:'a\"b';size:\"8\""`, WithGoEscapes())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if value := storage.GetTag("b").GetValue(); value != `q"r` {
+		t.Errorf("got value %q of b, expected %q", value, `q"r`)
+	}
+	gorm := storage.GetTag("gorm")
+	if gorm.GetParam("default").Value != `a"b` || gorm.GetParam("size").Value != "8" {
+		t.Errorf("got params %+v of gorm", gorm.GetParams())
+	}
+
+	storage, err = Parse(`json:"id"`, WithGoEscapes())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := storage.Set("note", "a \"b\"\n"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if written, expected := storage.String(), `json:"id" note:"a \"b\"\n"`; written != expected {
+		t.Errorf("got %q after Set, expected %q", written, expected)
+	}
+}
+
+func TestParseGoEscapesErrors(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`json:"a\qb"`, "invalid escape `\\q` at offset 1 of `json` tag value"},
+		{`env:"ab\x4"`, "invalid escape `\\x` at offset 2 of `env` tag value"},
+		{`env:"\ud800"`, "invalid escape `\\u` at offset 0 of `env` tag value"},
+		{"env:\"a\nb\"", "newline at offset 1 of `env` tag value"},
+		{`json:id`, "`json` tag value must be in quotation marks"},
+		{`a:"x\ y" b:"z"`, "invalid escape `\\ ` at offset 1 of `a` tag value"},
+		{`a:"x\;y"`, "invalid escape `\\;` at offset 1 of `a` tag value"},
+		{`a:"x\"`, unclosedBacktickErr},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(test.tag, WithGoEscapes()); err == nil || err.Error() != test.expected {
+			t.Errorf("%q: got error %v, expected %q", test.tag, err, test.expected)
+		}
+	}
+}
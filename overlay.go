@@ -0,0 +1,168 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type OverlayMode int
+
+const (
+	// OverlayReplace replaces the tags of a field with the overlay tags of the
+	// same names
+	OverlayReplace OverlayMode = iota
+	// OverlayAdd adds the params and options of the overlay tags to the tags of
+	// a field
+	OverlayAdd
+)
+
+// Overlay holds tags, written as a struct tag, to put on a field.
+type Overlay struct {
+	Mode OverlayMode
+	Tags string
+}
+
+// Overlays maps fields, written as `pkg.Type.Field`, to their overlay. pkg is
+// either the import path or the name of the package of Type.
+type Overlays map[string]Overlay
+
+// TaggedField is a struct field with its tags.
+type TaggedField struct {
+	Field reflect.StructField
+	Tags  Storage
+}
+
+// ParseOverlays reads overlays from lines holding a field and its tags. A `+`
+// between them adds the tags instead of replacing them. Blank lines and lines
+// starting with `#` are skipped.
+//
+//	github.com/acme/billing.Invoice.Total json:"total,string"
+//	billing.Invoice.Number + gorm:"uniqueIndex"
+func ParseOverlays(content string) (Overlays, error) {
+	overlays := make(Overlays)
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			return nil, errors.New(fmt.Sprintf(overlaySyntaxErr, i+1))
+		}
+		key := line[:end]
+
+		overlay := Overlay{Mode: OverlayReplace, Tags: strings.TrimSpace(line[end:])}
+		if strings.HasPrefix(overlay.Tags, "+") {
+			overlay.Mode = OverlayAdd
+			overlay.Tags = strings.TrimSpace(overlay.Tags[1:])
+		}
+		if overlay.Tags == "" {
+			return nil, errors.New(fmt.Sprintf(overlaySyntaxErr, i+1))
+		}
+
+		if _, _, _, err := splitOverlayKey(key); err != nil {
+			return nil, errors.New(fmt.Sprintf(overlayLineErr, i+1, err))
+		}
+		if _, err := Parse(overlay.Tags, WithStrict(), WithDialects()); err != nil {
+			return nil, errors.New(fmt.Sprintf(overlayLineErr, i+1, err))
+		}
+		if _, exists := overlays[key]; exists {
+			return nil, errors.New(fmt.Sprintf(overlayLineErr, i+1, fmt.Sprintf(overlayDuplicatedErr, key)))
+		}
+		overlays[key] = overlay
+	}
+	return overlays, nil
+}
+
+// Apply parses the tags of every field of structType as WithStrict and
+// WithDialects do and puts the overlays of the fields on them. Fields with invalid tags are returned
+// without tags, unless an overlay targets them. Overlays of structType
+// targeting fields it does not have are reported in the error, together with
+// the fields.
+func (overlays Overlays) Apply(structType reflect.Type) ([]TaggedField, error) {
+	if structType.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf(notStructTypeErr, structType))
+	}
+
+	fields := make([]TaggedField, structType.NumField())
+	invalid := make(map[int]error)
+	for i := range fields {
+		field := structType.Field(i)
+		storage, err := Parse(string(field.Tag), WithStrict(), WithDialects())
+		if err != nil {
+			invalid[i] = errors.New(fmt.Sprintf(fieldTagErr, field.Name, err))
+			storage = Storage{}
+		}
+		fields[i] = TaggedField{Field: field, Tags: storage}
+	}
+
+	keys := make([]string, 0, len(overlays))
+	for key := range overlays {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var missing []error
+	for _, key := range keys {
+		pkg, typeName, fieldName, err := splitOverlayKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if typeName != structType.Name() || (pkg != structType.PkgPath() && pkg != path.Base(structType.PkgPath())) {
+			continue
+		}
+
+		field, exists := structType.FieldByName(fieldName)
+		if !exists || len(field.Index) != 1 {
+			missing = append(missing, errors.New(fmt.Sprintf(overlayMissingFieldErr, key, structType)))
+			continue
+		}
+
+		if err := invalid[field.Index[0]]; err != nil {
+			return nil, err
+		}
+		overlay, err := Parse(overlays[key].Tags, WithStrict(), WithDialects())
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(overlayTagsErr, key, err))
+		}
+		fields[field.Index[0]].Tags = overlays[key].Mode.merge(fields[field.Index[0]].Tags, overlay)
+	}
+
+	return fields, errors.Join(missing...)
+}
+
+// merge puts overlay on storage, OverlayAdd merges the params and options of
+// the tags defined on both.
+func (mode OverlayMode) merge(storage, overlay Storage) Storage {
+	policy := MergeLastWins
+	if mode == OverlayAdd {
+		policy = MergeUnion
+	}
+	// Neither policy fails
+	_, _ = storage.Merge(overlay, policy)
+	return storage
+}
+
+// splitOverlayKey splits `pkg.Type.Field` from the right, as import paths may
+// contain dots.
+func splitOverlayKey(key string) (pkg, typeName, fieldName string, err error) {
+	rest, fieldName, _ := cutLast(key, ".")
+	pkg, typeName, _ = cutLast(rest, ".")
+	if pkg == "" || typeName == "" || fieldName == "" {
+		return "", "", "", errors.New(fmt.Sprintf(overlayKeyErr, key))
+	}
+	return pkg, typeName, fieldName, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
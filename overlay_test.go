@@ -0,0 +1,146 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+type overlayInvoice struct {
+	Number string `json:"number" gorm:"column:no;not null"`
+	Total  int    `json:"total,omitempty"`
+	Notes  string
+	overlayEmbedded
+}
+
+type overlayEmbedded struct {
+	Deleted bool
+}
+
+func TestParseOverlays(t *testing.T) {
+	const content = `
+# Overlays of vendored models
+github.com/kuzgoga/fogg.overlayInvoice.Total	json:"amount,string"
+fogg.overlayInvoice.Number + gorm:"uniqueIndex;size:32"
+`
+	overlays, err := ParseOverlays(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := Overlays{
+		"github.com/kuzgoga/fogg.overlayInvoice.Total": {Mode: OverlayReplace, Tags: `json:"amount,string"`},
+		"fogg.overlayInvoice.Number":                   {Mode: OverlayAdd, Tags: `gorm:"uniqueIndex;size:32"`},
+	}
+	if !reflect.DeepEqual(overlays, expected) {
+		t.Errorf("got %+v, expected %+v", overlays, expected)
+	}
+}
+
+func TestParseOverlaysErrors(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"pkg.Type.Field", `line 1 of overlay must hold a field and its tags`},
+		{"\npkg.Type.Field +", `line 2 of overlay must hold a field and its tags`},
+		{`Type.Field json:"a"`, `line 1 of overlay: invalid overlay key "Type.Field", expected pkg.Type.Field`},
+		{`pkg..Field json:"a"`, `line 1 of overlay: invalid overlay key "pkg..Field", expected pkg.Type.Field`},
+		{`pkg.Type.Field json:ab`, `line 1 of overlay: bad syntax for struct tag value at offset 5`},
+		{"pkg.Type.Field json:\"a\"\npkg.Type.Field json:\"b\"", `line 2 of overlay: duplicated overlay for "pkg.Type.Field"`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseOverlays(test.content)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseOverlays(%q) error = %v; want %q", test.content, err, test.expected)
+		}
+	}
+}
+
+func TestApplyOverlays(t *testing.T) {
+	overlays := Overlays{
+		"github.com/kuzgoga/fogg.overlayInvoice.Total": {Mode: OverlayReplace, Tags: `json:"amount,string"`},
+		"fogg.overlayInvoice.Number":                   {Mode: OverlayAdd, Tags: `gorm:"uniqueIndex;size:32;not null" validate:"required"`},
+		"fogg.overlayInvoice.Notes":                    {Mode: OverlayAdd, Tags: `json:"notes"`},
+		"other.overlayInvoice.Missing":                 {Mode: OverlayReplace, Tags: `json:"-"`},
+	}
+
+	fields, err := overlays.Apply(reflect.TypeOf(overlayInvoice{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(fields) != 4 || fields[0].Field.Name != "Number" || fields[3].Field.Name != "overlayEmbedded" {
+		t.Fatalf("unexpected fields %+v", fields)
+	}
+
+	total := fields[1].Tags.GetTag("json")
+	if total.GetValue() != "amount" || !reflect.DeepEqual(total.GetOptions(), []string{"string"}) {
+		t.Errorf("unexpected json tag of Total %+v", total)
+	}
+
+	number := fields[0].Tags.GetTag("gorm")
+	if number.GetValue() != "not null" || number.GetParam("column").Value != "no" || number.GetParam("size").Value != "32" {
+		t.Errorf("unexpected gorm tag of Number %+v", number)
+	}
+	if !reflect.DeepEqual(number.GetOptions(), []string{"not null", "uniqueIndex"}) {
+		t.Errorf("unexpected options of Number %v", number.GetOptions())
+	}
+	if fields[0].Tags.GetTag("json").GetValue() != "number" || !fields[0].Tags.HasTag("validate") {
+		t.Errorf("unexpected tags of Number %+v", fields[0].Tags)
+	}
+
+	const expected = `json:"number" gorm:"not null;uniqueIndex;column:no;size:32" validate:"required"`
+	if tag := fields[0].Tags.String(); tag != expected {
+		t.Errorf("got tags %q of Number, expected %q", tag, expected)
+	}
+
+	if fields[2].Tags.GetTag("json").GetValue() != "notes" {
+		t.Errorf("unexpected tags of Notes %+v", fields[2].Tags)
+	}
+}
+
+func TestApplyOverlaysErrors(t *testing.T) {
+	overlays := Overlays{
+		"fogg.overlayInvoice.Missing": {Mode: OverlayReplace, Tags: `json:"-"`},
+		"fogg.overlayInvoice.Deleted": {Mode: OverlayReplace, Tags: `json:"-"`},
+		"fogg.overlayInvoice.Total":   {Mode: OverlayReplace, Tags: `json:"total"`},
+	}
+
+	fields, err := overlays.Apply(reflect.TypeOf(overlayInvoice{}))
+	expected := "overlay \"fogg.overlayInvoice.Deleted\" targets a field which fogg.overlayInvoice does not have\n" +
+		"overlay \"fogg.overlayInvoice.Missing\" targets a field which fogg.overlayInvoice does not have"
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(fields) != 4 || fields[1].Tags.GetTag("json").GetValue() != "total" {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+
+	if _, err := overlays.Apply(reflect.TypeOf(0)); err == nil || err.Error() != `expected a struct type, got int` {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	invalid := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(0), Tag: `json:"a",xml:"b"`},
+		{Name: "B", Type: reflect.TypeOf(0), Tag: `gorm:"comment:it's" env:"B" env:"C"`},
+	})
+	fields, err = overlays.Apply(invalid)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(fields[0].Tags.Names()) != 0 || fields[1].Tags.String() != `gorm:"comment:it's" env:"B"` {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+
+	type apostrophe struct {
+		A int `gorm:"comment:it's" json:"a"`
+		B int `help:"user's name" env:"B" env:"C"`
+	}
+	fields, err = Overlays{"fogg.apostrophe.B": {Mode: OverlayAdd, Tags: `json:"b"`}}.Apply(reflect.TypeOf(apostrophe{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fields[0].Tags.String() != `gorm:"comment:it's" json:"a"` || fields[1].Tags.String() != `help:"user's name" env:"B" json:"b"` {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+}
@@ -1,11 +1,47 @@
 package fogg
 
-import "slices"
+import (
+	"slices"
+	"strings"
+)
+
+type Quote int
+
+const (
+	QuoteNone Quote = iota
+	QuoteSingle
+	QuoteDouble
+)
 
 type TagParam struct {
 	Name  string
 	Value string
 	Args  []string
+	// Raw is the value as written in the tag, with quotes and escape sequences
+	Raw string
+	// Quote is the kind of quotes removed from the value
+	Quote Quote
+	// KV holds the args written as `key:value`
+	KV KV
+}
+
+// KVPair is an arg written as `key:value`.
+type KVPair struct {
+	Key   string
+	Value string
+}
+
+// KV holds the `key:value` args of a param in the order they were written.
+type KV []KVPair
+
+// Get returns the value of the first arg with key.
+func (kv KV) Get(key string) (string, bool) {
+	for _, pair := range kv {
+		if pair.Key == key {
+			return pair.Value, true
+		}
+	}
+	return "", false
 }
 
 func (param *TagParam) HasArg(arg string) bool {
@@ -15,3 +51,20 @@ func (param *TagParam) HasArg(arg string) bool {
 		return false
 	}
 }
+
+// parseArgs splits the raw value of a param on the commas outside of quotes,
+// then unquotes and unescapes every arg. Args written as `key:value` are kept
+// in kv too, with their values unquoted.
+func parseArgs(raw string) (args []string, kv KV) {
+	const kvSeparator = ":"
+
+	for _, arg := range splitArgs(raw) {
+		arg = unescapeText(unquoteParamValue(arg), subtagEscapes)
+		args = append(args, arg)
+
+		if key, value, found := strings.Cut(arg, kvSeparator); found {
+			kv = append(kv, KVPair{Key: strings.TrimSpace(key), Value: unquoteParamValue(strings.TrimSpace(value))})
+		}
+	}
+	return args, kv
+}
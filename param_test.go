@@ -1,6 +1,10 @@
 package fogg
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestTagParamHasArg(t *testing.T) {
 	param := TagParam{
@@ -26,3 +30,50 @@ func TestTagParamHasArg(t *testing.T) {
 		}
 	}
 }
+
+func TestParamArgs(t *testing.T) {
+	tests := []struct {
+		tag  string
+		args []string
+		kv   KV
+	}{
+		{`index:idx_name,unique`, []string{"idx_name", "unique"}, nil},
+		{`index:,sort:desc,where:a = 'x,y'`, []string{"", "sort:desc", "where:a = 'x,y'"}, KV{{"sort", "desc"}, {"where", "a = 'x,y'"}}},
+		{`constraint:OnUpdate:CASCADE,OnDelete:SET NULL`, []string{"OnUpdate:CASCADE", "OnDelete:SET NULL"}, KV{{"OnUpdate", "CASCADE"}, {"OnDelete", "SET NULL"}}},
+		{`check:'a,b'`, []string{"a,b"}, nil},
+		{`check:"a,b",c`, []string{"a,b", "c"}, nil},
+		{`index:a,where:'x = \'y,z\''`, []string{"a", `where:'x = 'y,z''`}, KV{{"where", "x = 'y,z'"}}},
+		{`default:it\'s,ok`, []string{"it's", "ok"}, nil},
+		{`default:a\;b,c`, []string{"a;b", "c"}, nil},
+		{`index:`, []string{""}, nil},
+		{`index: name , sort : desc `, []string{"name ", " sort : desc"}, KV{{"sort", "desc"}}},
+	}
+
+	for _, test := range tests {
+		tag, err := ParseSubtag(test.tag, true)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.tag, err)
+			continue
+		}
+
+		name, _, _ := strings.Cut(test.tag, ":")
+		param := tag.GetParam(name)
+		if !reflect.DeepEqual(param.Args, test.args) {
+			t.Errorf("%s: got args %q, expected %q", test.tag, param.Args, test.args)
+		}
+		if !reflect.DeepEqual(param.KV, test.kv) {
+			t.Errorf("%s: got kv %q, expected %q", test.tag, param.KV, test.kv)
+		}
+	}
+}
+
+func TestKVGet(t *testing.T) {
+	kv := KV{{"sort", "desc"}, {"where", "a = 1"}, {"sort", "asc"}}
+
+	if value, found := kv.Get("sort"); !found || value != "desc" {
+		t.Errorf("Get(sort) = %q, %v; want desc, true", value, found)
+	}
+	if value, found := kv.Get("length"); found || value != "" {
+		t.Errorf("Get(length) = %q, %v; want \"\", false", value, found)
+	}
+}
@@ -3,168 +3,232 @@ package fogg
 import (
 	"errors"
 	"fmt"
-	"slices"
+	"strconv"
 	"strings"
 )
 
-func parseTagItems(name string, items []string, argsDelimiter string, trimSpaces bool) (Tag, error) {
+// tagItem is an item split out of a tag together with its source text.
+type tagItem struct {
+	text string
+	raw  string
+}
+
+func parseTagItems(name string, items []tagItem, trimSpaces bool, config *parseConfig) (Tag, error) {
 	const Separator = ":"
 
 	tag := Tag{
-		name:    name,
-		params:  make(map[string]TagParam),
-		options: make([]string, 0),
+		name:       name,
+		params:     make(map[string]TagParam),
+		options:    make([]string, 0),
+		rawOptions: make([]string, 0),
+		normalize:  config.normalize,
 	}
 
+	params := 0
 	for _, item := range items {
-		if strings.Contains(item, Separator) {
-			var key, value string
-			pair := strings.SplitN(item, Separator, 2)
+		if err := config.canceled(); err != nil {
+			return tag, err
+		}
+		if key, value, found := strings.Cut(item.text, Separator); found {
+			params++
+			if err := checkLimit("MaxParams", config.limits.MaxParams, params); err != nil {
+				return tag, err
+			}
 
+			_, rawValue, _ := strings.Cut(item.raw, Separator)
 			if trimSpaces {
-				key = strings.TrimSpace(pair[0])
-				value = strings.TrimSpace(pair[1])
-			} else {
-				key = pair[0]
-				value = pair[1]
+				key = strings.TrimSpace(key)
+				value = strings.TrimSpace(value)
+				rawValue = strings.TrimSpace(rawValue)
 			}
 
+			quote := paramValueQuote(value)
 			value = unquoteParamValue(value)
 
 			if len(key) == 0 {
 				return tag, errors.New(fmt.Sprintf(emptyNameTagErr, value))
 			}
 
-			if _, keyExist := tag.params[key]; keyExist {
-				return tag, errors.New(fmt.Sprintf(duplicatedParamErr, key))
+			args, kv := parseArgs(rawValue)
+			if err := checkLimit("MaxArgs", config.limits.MaxArgs, len(args)); err != nil {
+				return tag, err
 			}
-
 			param := TagParam{
 				Name:  key,
 				Value: value,
-				Args:  strings.Split(value, argsDelimiter),
+				Args:  args,
+				Raw:   rawValue,
+				Quote: quote,
+				KV:    kv,
 			}
 
-			tag.params[key] = param
+			if existingKey, keyExist := tag.paramKey(key); keyExist {
+				switch config.duplicates {
+				case DuplicatesError:
+					return tag, errors.New(fmt.Sprintf(duplicatedParamErr, key))
+				case DuplicatesFirst:
+					continue
+				case DuplicatesCollect:
+					tag.addDuplicates(existingKey, param)
+					continue
+				case DuplicatesLast:
+					delete(tag.params, existingKey)
+				}
+			}
+
+			tag.setParam(key, param)
 		} else {
 			if trimSpaces {
-				tag.options = append(tag.options, strings.TrimSpace(item))
+				tag.options = append(tag.options, strings.TrimSpace(item.text))
+				tag.rawOptions = append(tag.rawOptions, strings.TrimSpace(item.raw))
 			} else {
-				tag.options = append(tag.options, item)
+				tag.options = append(tag.options, item.text)
+				tag.rawOptions = append(tag.rawOptions, item.raw)
 			}
 		}
 	}
 	return tag, nil
 }
 
-func findEscapedBackslashesIndexes(s string) (escapedBackslashes []int, ignoredBackslashes []int) {
-	const EscapedBackslash = `\\`
-	var escapedIndexes []int
-	var ignoredIndexes []int
-	index := strings.Index(s, EscapedBackslash)
+type byteSet [256]bool
 
-	for index != -1 {
-		escapedIndexes = append(escapedIndexes, index)
-		ignoredIndexes = append(ignoredIndexes, index+1)
-		if index+len(EscapedBackslash) < len(s) {
-			index = strings.Index(s[index+len(EscapedBackslash):], EscapedBackslash)
-			if index != -1 {
-				index += escapedIndexes[len(escapedIndexes)-1] + len(EscapedBackslash)
-			}
-		} else {
-			break
+// itemBuffer tracks the item currently being split out of content. The item is
+// a plain substring until the first escape sequence is met, after that its
+// unescaped text is accumulated in buf.
+type itemBuffer struct {
+	content string
+	start   int
+	copied  int
+	buf     []byte
+	escaped bool
+}
+
+func (item *itemBuffer) replace(from, to int, char byte) {
+	if !item.escaped {
+		if item.buf == nil {
+			// Unescaped text is never longer than the rest of the content
+			item.buf = make([]byte, 0, len(item.content)-item.start)
 		}
+		item.buf = append(item.buf[:0], item.content[item.start:from]...)
+		item.escaped = true
+	} else {
+		item.buf = append(item.buf, item.content[item.copied:from]...)
 	}
-	return escapedIndexes, ignoredIndexes
+	item.buf = append(item.buf, char)
+	item.copied = to
 }
 
-func splitTagItems(content string, trimSpaces bool, backticks []string, delimiters []string, deleteEscapedSymbols bool) ([]string, error) {
-	const EscapeBackslash string = `\`
+func (item *itemBuffer) take(end int, trimSpaces bool) tagItem {
+	result := tagItem{raw: item.content[item.start:end]}
+	if item.escaped {
+		item.buf = append(item.buf, item.content[item.copied:end]...)
+		result.text = string(item.buf)
+	} else {
+		result.text = result.raw
+	}
 
-	var (
-		items              []string
-		backticksStack     []string
-		currentItem        string
-		isBackticksContent bool
-	)
+	if trimSpaces {
+		result.text = strings.TrimSpace(result.text)
+		result.raw = strings.TrimSpace(result.raw)
+	}
+	return result
+}
 
-	escapedBackslashes, ignoredBackslashes := findEscapedBackslashesIndexes(content)
+func (item *itemBuffer) reset(start int) {
+	item.start = start
+	item.copied = start
+	item.escaped = false
+}
 
-	for pos, char := range content {
-		// Process escaped backslashes
-		if slices.Contains(escapedBackslashes, pos) {
-			currentItem += string(char)
-			continue
-		}
-		if slices.Contains(ignoredBackslashes, pos) && deleteEscapedSymbols {
-			continue
-		} else if slices.Contains(ignoredBackslashes, pos) && !deleteEscapedSymbols {
-			currentItem += string(char)
-			continue
+func newByteSet(chars []string) (set byteSet) {
+	for _, char := range chars {
+		if len(char) == 1 {
+			set[char[0]] = true
 		}
+	}
+	return set
+}
 
-		isPriorBackslashEscaped := pos != 0 && slices.Contains(ignoredBackslashes, pos-1)
-		priorBackslash := pos != 0 && string(content[pos-1]) == EscapeBackslash && !isPriorBackslashEscaped
+func countItemsUpperBound(content string, delimiters []string) int {
+	count := 1
+	for _, delimiter := range delimiters {
+		count += strings.Count(content, delimiter)
+	}
+	return count
+}
 
-		// Skip backslashes used for escaping symbols
-		if priorBackslash && slices.Contains(backticks, string(char)) && deleteEscapedSymbols {
-			currentItem = currentItem[:len(currentItem)-1] + string(char)
-			continue
-		}
+func splitTagItems(content string, trimSpaces bool, backticks []string, delimiters []string, deleteEscapedSymbols bool) ([]string, error) {
+	items := make([]string, 0, countItemsUpperBound(content, delimiters))
+	err := scanTagItems(content, trimSpaces, backticks, delimiters, deleteEscapedSymbols, func(item tagItem) {
+		items = append(items, item.text)
+	})
+	return items, err
+}
+
+func splitRawTagItems(content string, trimSpaces bool, backticks []string, delimiters []string, deleteEscapedSymbols bool) ([]tagItem, error) {
+	items := make([]tagItem, 0, countItemsUpperBound(content, delimiters))
+	err := scanTagItems(content, trimSpaces, backticks, delimiters, deleteEscapedSymbols, func(item tagItem) {
+		items = append(items, item)
+	})
+	return items, err
+}
+
+// scanTagItems scans content once, byte by byte. Every special character is
+// ASCII, so scanning bytes never splits a multibyte rune. Items are sliced
+// from content and only copied when they contain an escape sequence.
+func scanTagItems(content string, trimSpaces bool, backticks []string, delimiters []string, deleteEscapedSymbols bool, emit func(item tagItem)) error {
+	const EscapeBackslash byte = '\\'
+
+	quotes := newByteSet(backticks)
+	separators := newByteSet(delimiters)
 
-		charStr := string(char)
-		if slices.Contains(backticks, charStr) && !priorBackslash {
-			if len(backticksStack) > 0 && backticksStack[len(backticksStack)-1] == charStr {
-				backticksStack = backticksStack[:len(backticksStack)-1] // Pop from stack
-				if len(backticksStack) == 0 {
-					isBackticksContent = false
+	var (
+		quotesStackBuf [8]byte
+		quotesStack    = quotesStackBuf[:0]
+		item           = itemBuffer{content: content}
+	)
+
+	for pos := 0; pos < len(content); pos++ {
+		char := content[pos]
+
+		switch {
+		case char == EscapeBackslash && pos+1 < len(content):
+			next := content[pos+1]
+			switch {
+			case next == EscapeBackslash, quotes[next]:
+				if deleteEscapedSymbols {
+					item.replace(pos, pos+2, next)
 				}
+				pos++
+			case separators[next]:
+				// Escaped delimiters never split and always lose their backslash
+				item.replace(pos, pos+2, next)
+				pos++
+			}
+		case quotes[char]:
+			if depth := len(quotesStack); depth > 0 && quotesStack[depth-1] == char {
+				quotesStack = quotesStack[:depth-1]
 			} else {
-				backticksStack = append(backticksStack, charStr)
-				isBackticksContent = true
+				quotesStack = append(quotesStack, char)
 			}
-			currentItem += charStr
-			continue
-		}
-
-		// Only split on delimiters when not inside quotes
-		if slices.Contains(delimiters, charStr) && !isBackticksContent && !priorBackslash {
-			if currentItem != "" {
-				if trimSpaces {
-					items = append(items, strings.TrimSpace(currentItem))
-				} else {
-					items = append(items, currentItem)
-				}
+		case separators[char] && len(quotesStack) == 0:
+			// Only split on delimiters when not inside quotes
+			if pos > item.start {
+				emit(item.take(pos, trimSpaces))
 			}
-			currentItem = ""
-		} else {
-			currentItem += charStr
+			item.reset(pos + 1)
 		}
 	}
 
-	if currentItem != "" {
-		if trimSpaces {
-			items = append(items, strings.TrimSpace(currentItem))
-		} else {
-			items = append(items, currentItem)
-		}
+	if len(content) > item.start {
+		emit(item.take(len(content), trimSpaces))
 	}
 
-	if len(backticksStack) != 0 {
-		return items, errors.New(unclosedBacktickErr)
+	if len(quotesStack) != 0 {
+		return errors.New(unclosedBacktickErr)
 	}
 
-	// Handle escaped delimiters
-	for i, item := range items {
-		for _, backtick := range backticks {
-			items[i] = strings.ReplaceAll(item, "\\"+backtick, backtick)
-		}
-		for _, delimiter := range delimiters {
-			items[i] = strings.ReplaceAll(item, "\\"+delimiter, delimiter)
-		}
-	}
-
-	return items, nil
+	return nil
 }
 
 func unquoteTagContent(name, content string) (string, error) {
@@ -180,17 +244,115 @@ func unquoteTagContent(name, content string) (string, error) {
 	}
 }
 
-func ParseSubtag(value string, trimSpaces bool) (Tag, error) {
-	const argsDelimiter = ","
+// splitGoTags splits content on the spaces outside of quoted values, skipping
+// the escape sequences of values as Go string literals do. Tags are returned as
+// written, so that their values are decoded from the untouched bytes.
+func splitGoTags(content string) ([]string, error) {
+	var tags []string
+
+	start, quoted := 0, false
+	for pos := 0; pos < len(content); pos++ {
+		switch char := content[pos]; {
+		case char == escapeBackslash && quoted:
+			pos++
+		case char == tagBacktick:
+			quoted = !quoted
+		case char == tagDelimiter && !quoted:
+			if pos > start {
+				tags = append(tags, content[start:pos])
+			}
+			start = pos + 1
+		}
+	}
+
+	if quoted {
+		return nil, errors.New(unclosedBacktickErr)
+	}
+	if len(content) > start {
+		tags = append(tags, content[start:])
+	}
+	return tags, nil
+}
+
+// escapeLiteralQuotes escapes the double quotes of content decoded from a Go
+// string literal, as they were escaped in the literal, so that subtags read
+// them as text rather than as quotes.
+func escapeLiteralQuotes(content string) string {
+	if !strings.Contains(content, `"`) {
+		return content
+	}
+
+	var builder strings.Builder
+	backslashes := 0
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+		if char == tagBacktick && backslashes%2 == 0 {
+			builder.WriteByte(escapeBackslash)
+		}
+		if char == escapeBackslash {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+		builder.WriteByte(char)
+	}
+	return builder.String()
+}
+
+// unquoteGoTagContent unquotes content and decodes its escapes as in Go string
+// literals. Errors hold offsets within the unquoted content.
+func unquoteGoTagContent(name, content string) (string, error) {
+	content, err := unquoteTagContent(name, content)
+	if err != nil {
+		return "", err
+	}
+	if !strings.ContainsAny(content, "\\\n") {
+		return content, nil
+	}
+
+	var builder strings.Builder
+	for rest := content; rest != ""; {
+		offset := len(content) - len(rest)
+		if rest[0] == '\n' {
+			return "", errors.New(fmt.Sprintf(goNewlineErr, offset, name))
+		}
+
+		char, multibyte, tail, err := strconv.UnquoteChar(rest, tagBacktick)
+		if err != nil {
+			escape := rest[:min(2, len(rest))]
+			return "", errors.New(fmt.Sprintf(goEscapeErr, escape, offset, name))
+		}
+		if multibyte {
+			builder.WriteRune(char)
+		} else {
+			builder.WriteByte(byte(char))
+		}
+		rest = tail
+	}
+	return builder.String(), nil
+}
+
+func ParseSubtag(value string, trimSpaces bool, options ...ParseOption) (Tag, error) {
+	return parseSubtag(value, trimSpaces, newParseConfig(options))
+}
+
+func parseSubtag(value string, trimSpaces bool, config *parseConfig) (Tag, error) {
 	subtagBackticks := []string{`'`, `"`}
 	subtagDelimiters := []string{";"}
 
-	tagItems, err := splitTagItems(value, trimSpaces, subtagBackticks, subtagDelimiters, true)
+	if err := checkLimit("MaxLength", config.limits.MaxLength, len(value)); err != nil {
+		return Tag{}, err
+	}
+	if err := checkQuoteDepth(value, newByteSet(subtagBackticks), config.limits.MaxQuoteDepth); err != nil {
+		return Tag{}, err
+	}
+
+	tagItems, err := splitRawTagItems(value, trimSpaces, subtagBackticks, subtagDelimiters, true)
 	if err != nil {
 		return Tag{}, err
 	}
 
-	tag, err := parseTagItems("", tagItems, argsDelimiter, true)
+	tag, err := parseTagItems("", tagItems, true, config)
 	if err != nil {
 		return Tag{}, err
 	}
@@ -198,10 +360,23 @@ func ParseSubtag(value string, trimSpaces bool) (Tag, error) {
 	if len(tag.options) >= 1 {
 		tag.value = tag.options[0]
 	}
+	tag.content = value
 
 	return tag, nil
 }
 
+func paramValueQuote(value string) Quote {
+	if len(value) >= 2 && value[0] == value[len(value)-1] {
+		switch value[0] {
+		case '\'':
+			return QuoteSingle
+		case '"':
+			return QuoteDouble
+		}
+	}
+	return QuoteNone
+}
+
 func unquoteParamValue(value string) string {
 	if len(value) >= 2 {
 		if (value[0] == '\'' && value[len(value)-1] == '\'') || (value[0] == '"' && value[len(value)-1] == '"') {
@@ -4,11 +4,10 @@ import (
 	"fmt"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 )
 
-const argsDelimiter = `,`
-
 func TestParseFunction(t *testing.T) {
 	tagContent := `not null;default:'one';check:', n > 1'`
 	expectedItems := []string{"not null", "default:'one'", "check:', n > 1'"}
@@ -90,44 +89,98 @@ func TestSplitTagTrimSpaces(t *testing.T) {
 	}
 }
 
-/* --- FindEscapedBackslashes function tests --- */
-func TestFindEscapedBackslashesIndexes(t *testing.T) {
-	input := `This is a test string with escaped backslashes \\ and more \\ backslashes`
-	expectedEscaped := []int{47, 59}
-	expectedIgnored := []int{48, 60}
+func TestEscapedBackslashBeforeQuotedDelimiter(t *testing.T) {
+	tagContent := `default:'a\\;b';check:x\;y`
+	expectedItems := []string{`default:'a\;b'`, `check:x;y`}
 
-	escaped, ignored := findEscapedBackslashesIndexes(input)
+	backticks := []string{`'`}
+	delimiters := []string{`;`}
+	items, err := splitTagItems(tagContent, true, backticks, delimiters, true)
 
-	if !slices.Equal(escaped, expectedEscaped) {
-		t.Errorf("expected escaped indexes %v, got %v", expectedEscaped, escaped)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
 	}
 
-	if !slices.Equal(ignored, expectedIgnored) {
-		t.Errorf("expected ignored indexes %v, got %v", expectedIgnored, ignored)
+	if !slices.Equal(items, expectedItems) {
+		t.Errorf("expected %v, got %v", expectedItems, items)
 	}
 }
 
-func TestFindEscapedBackslashesIndexesWithBreak(t *testing.T) {
-	input := `This is a test string with an escaped backslash at the end \\`
-	expectedEscaped := []int{59}
-	expectedIgnored := []int{60}
+func TestSplitTagItemsMultibyte(t *testing.T) {
+	tagContent := `label:'Время; создания';readonly;hint:Ж\;Ж`
+	expectedItems := []string{`label:'Время; создания'`, `readonly`, `hint:Ж;Ж`}
+
+	backticks := []string{`'`}
+	delimiters := []string{`;`}
+	items, err := splitTagItems(tagContent, true, backticks, delimiters, true)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !slices.Equal(items, expectedItems) {
+		t.Errorf("expected %v, got %v", expectedItems, items)
+	}
+}
+
+func TestSplitTagItemsAllocations(t *testing.T) {
+	backticks := []string{`'`, `"`}
+	delimiters := []string{`;`}
+
+	tests := []struct {
+		content   string
+		maxAllocs float64
+	}{
+		{`not null;default:'one';check:', n > 1';index:,unique`, 1},
+		{`default:'a\'b';comment:x\;y;size:256`, 4},
+		{strings.Repeat(`\\`, 1000), 3},
+	}
+
+	for _, test := range tests {
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = splitTagItems(test.content, true, backticks, delimiters, true)
+		})
+		if allocs > test.maxAllocs {
+			t.Errorf("splitTagItems(%.20q) made %v allocations; want at most %v", test.content, allocs, test.maxAllocs)
+		}
+	}
+}
 
-	escaped, ignored := findEscapedBackslashesIndexes(input)
+func BenchmarkSplitTagItems(b *testing.B) {
+	const tagContent = `column:customer_id;type:varchar(100);not null;default:'a\'b';index:idx_customer,unique;check:', n > 1'`
+	backticks := []string{`'`, `"`}
+	delimiters := []string{`;`}
 
-	if !slices.Equal(escaped, expectedEscaped) {
-		t.Errorf("expected escaped indexes %v, got %v", expectedEscaped, escaped)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = splitTagItems(tagContent, true, backticks, delimiters, true)
 	}
+}
+
+func BenchmarkSplitTagItemsBackslashes(b *testing.B) {
+	tagContent := strings.Repeat(`\\`, 4096) + `;` + strings.Repeat(`a\;`, 4096)
+	backticks := []string{`'`, `"`}
+	delimiters := []string{`;`}
 
-	if !slices.Equal(ignored, expectedIgnored) {
-		t.Errorf("expected ignored indexes %v, got %v", expectedIgnored, ignored)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = splitTagItems(tagContent, true, backticks, delimiters, true)
 	}
 }
 
 /* --- Distribution function tests --- */
+func textItems(items []string) []tagItem {
+	result := make([]tagItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, tagItem{text: item, raw: item})
+	}
+	return result
+}
+
 func TestDistributeItemsToOptionsAndParams(t *testing.T) {
 	items := []string{"option1", "param1:value1", "option2", "param2:value2"}
 
-	tag, err := parseTagItems("", items, argsDelimiter, true)
+	tag, err := parseTagItems("", textItems(items), true, &parseConfig{})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -149,7 +202,7 @@ func TestDistributeItemsToOptionsAndParams(t *testing.T) {
 func TestDistributeItemsToOptionsAndParamsWithSpaces(t *testing.T) {
 	items := []string{" option1 ", " param1 : value1 ", " option2 ", " param2 : value2 "}
 
-	tag, err := parseTagItems("", items, argsDelimiter, true)
+	tag, err := parseTagItems("", textItems(items), true, &parseConfig{})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -171,7 +224,7 @@ func TestDistributeItemsToOptionsAndParamsWithSpaces(t *testing.T) {
 func TestDistributeItemsToOptionsAndParamsWithDuplicateParam(t *testing.T) {
 	items := []string{"param1:value1", "param1:value2"}
 
-	_, err := parseTagItems("", items, argsDelimiter, true)
+	_, err := parseTagItems("", textItems(items), true, &parseConfig{})
 	if err == nil {
 		t.Errorf("expected error for duplicate param, got nil")
 	}
@@ -180,7 +233,7 @@ func TestDistributeItemsToOptionsAndParamsWithDuplicateParam(t *testing.T) {
 func TestDistributeItemsToOptionsAndParamsWithEmptyKey(t *testing.T) {
 	items := []string{"param1:value1", ":value2"}
 
-	_, err := parseTagItems("", items, argsDelimiter, true)
+	_, err := parseTagItems("", textItems(items), true, &parseConfig{})
 	if err == nil {
 		t.Errorf("expected error for empty key, got nil")
 	}
@@ -189,7 +242,7 @@ func TestDistributeItemsToOptionsAndParamsWithEmptyKey(t *testing.T) {
 func TestDistributeItemsToOptionsAndParamsWithoutTrimSpaces(t *testing.T) {
 	items := []string{" option1 ", " param1 : value1 ", " option2 ", " param2 : value2 "}
 
-	tag, err := parseTagItems("", items, argsDelimiter, false)
+	tag, err := parseTagItems("", textItems(items), false, &parseConfig{})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -244,14 +297,19 @@ func TestParseSubtag(t *testing.T) {
 				Name:  "default",
 				Value: `"SomeValue`,
 				Args:  []string{`"SomeValue`},
+				Raw:   `'\"SomeValue'`,
+				Quote: QuoteSingle,
 			},
 			"foreignKey": {
 				Name:  "foreignKey",
 				Value: "CustomerId",
 				Args:  []string{"CustomerId"},
+				Raw:   "CustomerId",
 			},
 		},
-		options: []string{},
+		options:    []string{},
+		rawOptions: []string{},
+		content:    validTag,
 	}
 
 	tag, err := ParseSubtag(validTag, true)
@@ -325,3 +383,53 @@ func TestTagWithSpaces(t *testing.T) {
 		t.Errorf("expected tag `ui` to be present")
 	}
 }
+
+func TestParamValueQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Quote
+	}{
+		{"'value'", QuoteSingle},
+		{`"value"`, QuoteDouble},
+		{"value", QuoteNone},
+		{"'value\"", QuoteNone},
+		{"'", QuoteNone},
+	}
+
+	for _, test := range tests {
+		if result := paramValueQuote(test.input); result != test.expected {
+			t.Errorf("paramValueQuote(%s) = %v; want %v", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestParseSubtagRawValues(t *testing.T) {
+	tests := []struct {
+		tag         string
+		value       string
+		raw         string
+		quote       Quote
+		rawOptions  []string
+		optionsText []string
+	}{
+		{`default:'a;b';not null`, "a;b", "'a;b'", QuoteSingle, []string{"not null"}, []string{"not null"}},
+		{`default:a\;b; it\'s `, "a;b", `a\;b`, QuoteNone, []string{`it\'s`}, []string{`it's`}},
+		{`default: "a b" `, "a b", `"a b"`, QuoteDouble, []string{}, []string{}},
+	}
+
+	for _, test := range tests {
+		tag, err := ParseSubtag(test.tag, true)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+
+		param := tag.GetParam("default")
+		if param.Value != test.value || param.Raw != test.raw || param.Quote != test.quote {
+			t.Errorf("%s: got value %q, raw %q, quote %v", test.tag, param.Value, param.Raw, param.Quote)
+		}
+		if !slices.Equal(tag.GetRawOptions(), test.rawOptions) || !slices.Equal(tag.GetOptions(), test.optionsText) {
+			t.Errorf("%s: got options %q, raw options %q", test.tag, tag.GetOptions(), tag.GetRawOptions())
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	protoItemsDelimiter = ","
+	protoParamDelimiter = "="
+	protoDefaultPrefix  = "def="
+	protoMaxFieldNumber = 1<<29 - 1
+)
+
+type ProtoCardinality int
+
+const (
+	ProtoOptional ProtoCardinality = iota + 1
+	ProtoRequired
+	ProtoRepeated
+)
+
+var protoWireTypes = []string{"varint", "zigzag32", "zigzag64", "fixed32", "fixed64", "bytes", "group"}
+
+// ProtoField describes a field of generated protobuf code, read from its
+// protobuf, protobuf_key or protobuf_val tag.
+type ProtoField struct {
+	// WireType is the encoding of the field, one of varint, zigzag32, zigzag64,
+	// fixed32, fixed64, bytes and group.
+	WireType    string
+	Number      int
+	Cardinality ProtoCardinality
+	Name        string
+	JSONName    string
+	Packed      bool
+	Proto3      bool
+	Enum        string
+	Oneof       bool
+	Weak        string
+	// Default holds everything after `def=`, commas included.
+	Default    string
+	HasDefault bool
+	// Key and Value describe the entries of map fields.
+	Key   *ProtoField
+	Value *ProtoField
+}
+
+// ParseProtoField parses the content of a protobuf tag. Unknown items are
+// ignored, as the protobuf runtime does.
+func ParseProtoField(content string) (ProtoField, error) {
+	field := ProtoField{}
+
+	wireType, rest, _ := strings.Cut(content, protoItemsDelimiter)
+	number, rest, found := strings.Cut(rest, protoItemsDelimiter)
+	if wireType == "" || number == "" {
+		return field, errors.New(fmt.Sprintf(protoTooShortErr, content))
+	}
+
+	if !slices.Contains(protoWireTypes, wireType) {
+		return field, errors.New(fmt.Sprintf(protoWireTypeErr, wireType))
+	}
+	field.WireType = wireType
+
+	var err error
+	if field.Number, err = strconv.Atoi(number); err != nil || field.Number < 1 || field.Number > protoMaxFieldNumber {
+		return field, errors.New(fmt.Sprintf(protoFieldNumberErr, number))
+	}
+
+	for found {
+		if strings.HasPrefix(rest, protoDefaultPrefix) {
+			field.Default = rest[len(protoDefaultPrefix):]
+			field.HasDefault = true
+			break
+		}
+
+		var item string
+		item, rest, found = strings.Cut(rest, protoItemsDelimiter)
+		key, value, _ := strings.Cut(item, protoParamDelimiter)
+		switch key {
+		case "opt":
+			field.Cardinality = ProtoOptional
+		case "req":
+			field.Cardinality = ProtoRequired
+		case "rep":
+			field.Cardinality = ProtoRepeated
+		case "packed":
+			field.Packed = true
+		case "proto3":
+			field.Proto3 = true
+		case "oneof":
+			field.Oneof = true
+		case "name":
+			field.Name = value
+		case "json":
+			field.JSONName = value
+		case "enum":
+			field.Enum = value
+		case "weak":
+			field.Weak = value
+		}
+	}
+
+	return field, nil
+}
+
+// ProtoFieldOf reads the protobuf tag of a struct field, together with the
+// protobuf_key and protobuf_val tags of map fields. It reports false when the
+// field has no protobuf tag, like XXX_ fields and oneof wrappers.
+func ProtoFieldOf(field reflect.StructField) (ProtoField, bool, error) {
+	content, exists := field.Tag.Lookup("protobuf")
+	if !exists {
+		return ProtoField{}, false, nil
+	}
+
+	protoField, err := ParseProtoField(content)
+	if err != nil {
+		return protoField, true, err
+	}
+
+	for _, entry := range []struct {
+		tag   string
+		field **ProtoField
+	}{{"protobuf_key", &protoField.Key}, {"protobuf_val", &protoField.Value}} {
+		if content, exists := field.Tag.Lookup(entry.tag); exists {
+			entryField, err := ParseProtoField(content)
+			if err != nil {
+				return protoField, true, err
+			}
+			*entry.field = &entryField
+		}
+	}
+
+	return protoField, true, nil
+}
+
+// parseProtoSubtag keeps the flags of a protobuf tag as options, the wire type
+// first, and its `key=value` items as params. The tag is checked by
+// ParseProtoField and ProtoFieldOf.
+func parseProtoSubtag(content string) (Tag, error) {
+	wireType, _, _ := strings.Cut(content, protoItemsDelimiter)
+
+	tag := Tag{
+		value:      wireType,
+		params:     make(map[string]TagParam),
+		options:    make([]string, 0),
+		rawOptions: make([]string, 0),
+		content:    content,
+	}
+
+	rest := content
+	for rest != "" {
+		var item string
+		if strings.HasPrefix(rest, protoDefaultPrefix) {
+			item, rest = rest, ""
+		} else {
+			item, rest, _ = strings.Cut(rest, protoItemsDelimiter)
+		}
+
+		if key, value, found := strings.Cut(item, protoParamDelimiter); found {
+			tag.params[key] = TagParam{Name: key, Value: value, Args: []string{value}, Raw: value}
+		} else {
+			tag.options = append(tag.options, item)
+		}
+	}
+	tag.rawOptions = tag.options
+
+	return tag, nil
+}
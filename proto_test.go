@@ -0,0 +1,146 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProtoField(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected ProtoField
+	}{
+		{`varint,1,opt,name=id,proto3`, ProtoField{WireType: "varint", Number: 1, Cardinality: ProtoOptional, Name: "id", Proto3: true}},
+		{`bytes,2,rep,name=tags,proto3`, ProtoField{WireType: "bytes", Number: 2, Cardinality: ProtoRepeated, Name: "tags", Proto3: true}},
+		{`varint,3,rep,packed,name=ids`, ProtoField{WireType: "varint", Number: 3, Cardinality: ProtoRepeated, Packed: true, Name: "ids"}},
+		{`varint,4,opt,name=status,proto3,enum=shop.Status`, ProtoField{WireType: "varint", Number: 4, Cardinality: ProtoOptional, Name: "status", Proto3: true, Enum: "shop.Status"}},
+		{`bytes,5,opt,name=email,proto3,oneof`, ProtoField{WireType: "bytes", Number: 5, Cardinality: ProtoOptional, Name: "email", Proto3: true, Oneof: true}},
+		{`bytes,6,opt,name=display_name,json=displayName,proto3`, ProtoField{WireType: "bytes", Number: 6, Cardinality: ProtoOptional, Name: "display_name", JSONName: "displayName", Proto3: true}},
+		{`bytes,7,opt,name=greeting,def=hello, world,rep`, ProtoField{WireType: "bytes", Number: 7, Cardinality: ProtoOptional, Name: "greeting", Default: "hello, world,rep", HasDefault: true}},
+		{`fixed64,8,opt,name=empty,def=`, ProtoField{WireType: "fixed64", Number: 8, Cardinality: ProtoOptional, Name: "empty", HasDefault: true}},
+		{`group,9,opt,name=Result,weak=shop.Result`, ProtoField{WireType: "group", Number: 9, Cardinality: ProtoOptional, Name: "Result", Weak: "shop.Result"}},
+		{`zigzag64,536870911,req,name=delta,unknown,`, ProtoField{WireType: "zigzag64", Number: 536870911, Cardinality: ProtoRequired, Name: "delta"}},
+		{`fixed32,10`, ProtoField{WireType: "fixed32", Number: 10}},
+	}
+
+	for _, test := range tests {
+		field, err := ParseProtoField(test.content)
+		if err != nil {
+			t.Errorf("ParseProtoField(%q): unexpected error: %s", test.content, err)
+		} else if !reflect.DeepEqual(field, test.expected) {
+			t.Errorf("ParseProtoField(%q) = %+v; want %+v", test.content, field, test.expected)
+		}
+	}
+}
+
+func TestParseProtoFieldErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{``, `protobuf tag "" must start with a wire type and a field number`},
+		{`bytes`, `protobuf tag "bytes" must start with a wire type and a field number`},
+		{`bytes,,opt`, `protobuf tag "bytes,,opt" must start with a wire type and a field number`},
+		{`string,1,opt`, `unknown protobuf wire type "string"`},
+		{`bytes,x,opt`, `invalid protobuf field number "x"`},
+		{`bytes,0,opt`, `invalid protobuf field number "0"`},
+		{`bytes,536870912`, `invalid protobuf field number "536870912"`},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseProtoField(test.content); err == nil || err.Error() != test.err {
+			t.Errorf("ParseProtoField(%q): got error `%v`, expected `%s`", test.content, err, test.err)
+		}
+	}
+}
+
+type protoOrderV1 struct {
+	Id     int64            `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Labels map[string]int32 `protobuf:"bytes,2,rep,name=labels,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Kind   isOrderKind      `protobuf_oneof:"kind"`
+	Note   string           `protobuf:"bytes,3,opt,name=note,proto3"`
+}
+
+type protoOrderV2 struct {
+	Id   int64  `protobuf:"varint,1,opt,name=id,proto3"`
+	Note string `protobuf:"bytes,4,opt,name=note,proto3"`
+}
+
+type isOrderKind interface{}
+
+func protoNumbers(t *testing.T, structType reflect.Type) map[string]int {
+	numbers := make(map[string]int)
+	for i := 0; i < structType.NumField(); i++ {
+		field, ok, err := ProtoFieldOf(structType.Field(i))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ok {
+			numbers[field.Name] = field.Number
+		}
+	}
+	return numbers
+}
+
+func TestProtoFieldOf(t *testing.T) {
+	structType := reflect.TypeOf(protoOrderV1{})
+
+	field, ok, err := ProtoFieldOf(structType.Field(1))
+	if err != nil || !ok {
+		t.Fatalf("expected map field, got %v, %v", ok, err)
+	}
+	if field.Key == nil || field.Key.WireType != "bytes" || field.Value == nil || field.Value.Number != 2 {
+		t.Errorf("unexpected map entries %+v, %+v", field.Key, field.Value)
+	}
+
+	if _, ok, _ := ProtoFieldOf(structType.Field(2)); ok {
+		t.Errorf("expected oneof wrapper without protobuf tag to be skipped")
+	}
+
+	before, after := protoNumbers(t, structType), protoNumbers(t, reflect.TypeOf(protoOrderV2{}))
+	renumbered := []string{}
+	for name, number := range after {
+		if previous, exists := before[name]; exists && previous != number {
+			renumbered = append(renumbered, name)
+		}
+	}
+	if !reflect.DeepEqual(renumbered, []string{"note"}) {
+		t.Errorf("expected renumbered note field, got %v", renumbered)
+	}
+}
+
+func TestParseProtoDialect(t *testing.T) {
+	storage, err := Parse(`protobuf:"bytes,7,opt,name=greeting,json=hi,def=a,b" protobuf_oneof:"kind"`, WithDialects())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tag := storage.GetTag("protobuf")
+	if tag.GetValue() != "bytes" || !reflect.DeepEqual(tag.GetOptions(), []string{"bytes", "7", "opt"}) {
+		t.Errorf("unexpected protobuf tag %+v", tag)
+	}
+	if tag.GetParamOr("name", "") != "greeting" || tag.GetParamOr("json", "") != "hi" || tag.GetParamOr("def", "") != "a,b" {
+		t.Errorf("unexpected protobuf params %+v", tag.GetParams())
+	}
+	if storage.GetTag("protobuf_oneof").GetValue() != "kind" {
+		t.Errorf("unexpected protobuf_oneof tag %+v", storage.GetTag("protobuf_oneof"))
+	}
+
+	invalid := map[string]string{
+		"text,1": `unknown protobuf wire type "text"`,
+		"varint": `protobuf tag "varint" must start with a wire type and a field number`,
+	}
+	for content, expected := range invalid {
+		storage, err := Parse(`json:"id" protobuf:"`+content+`"`, WithDialects())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", content, err)
+		} else if storage.GetTag("protobuf").GetContent() != content {
+			t.Errorf("%q: got content %q", content, storage.GetTag("protobuf").GetContent())
+		}
+
+		field := reflect.StructField{Name: "A", Type: reflect.TypeOf(0), Tag: reflect.StructTag(`protobuf:"` + content + `"`)}
+		if _, _, err := ProtoFieldOf(field); err == nil || err.Error() != expected {
+			t.Errorf("%q: got error %v from ProtoFieldOf, expected %q", content, err, expected)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Retag derives a struct type from t with the tags of its fields rewritten by
+// fn, which may change the tags, parsed as WithStrict and WithDialects do,
+// through the Storage methods. Fields of nested struct types are rewritten too, also behind
+// pointers, slices, arrays and maps. Named struct types are replaced by derived struct types without a
+// name, unless they have methods or unexported fields, or refer to themselves.
+// Values convert between t and the derived type with ConvertRetagged.
+func Retag(t reflect.Type, fn func(field reflect.StructField, s *Storage) error) (reflect.Type, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf(notStructTypeErr, t))
+	}
+	retagger := retagger{fn: fn, visiting: map[reflect.Type]bool{t: true}}
+	return retagger.retagStruct(t)
+}
+
+// retagger keeps the named struct types being rewritten, to leave the types
+// referring to themselves as they are.
+type retagger struct {
+	fn       func(field reflect.StructField, s *Storage) error
+	visiting map[reflect.Type]bool
+}
+
+func (retagger *retagger) retagStruct(t reflect.Type) (reflect.Type, error) {
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		field := t.Field(i)
+		if !field.IsExported() {
+			return nil, errors.New(fmt.Sprintf(retagUnexportedErr, t, field.Name))
+		}
+
+		storage, err := Parse(string(field.Tag), WithStrict(), WithDialects())
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(fieldTagErr, field.Name, err))
+		}
+		if err := retagger.fn(field, &storage); err != nil {
+			return nil, err
+		}
+
+		field.Tag = reflect.StructTag(storage.String())
+		if field.Type, err = retagger.retagType(field.Type); err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	return structOf(t, fields)
+}
+
+// retagType rewrites the struct types t is built of.
+func (retagger *retagger) retagType(t reflect.Type) (reflect.Type, error) {
+	if t.Name() != "" {
+		if !retagger.retaggable(t) {
+			return t, nil
+		}
+		retagger.visiting[t] = true
+		defer delete(retagger.visiting, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return retagger.retagStruct(t)
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		elem, err := retagger.retagType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		switch t.Kind() {
+		case reflect.Pointer:
+			return reflect.PointerTo(elem), nil
+		case reflect.Slice:
+			return reflect.SliceOf(elem), nil
+		case reflect.Array:
+			return reflect.ArrayOf(t.Len(), elem), nil
+		default:
+			key, err := retagger.retagType(t.Key())
+			if err != nil {
+				return nil, err
+			}
+			return reflect.MapOf(key, elem), nil
+		}
+	default:
+		return t, nil
+	}
+}
+
+// retaggable reports whether the named type t is a struct type which may be
+// replaced without losing methods, e.g. MarshalJSON, or unexported fields.
+func (retagger *retagger) retaggable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || retagger.visiting[t] || t.NumMethod() > 0 || reflect.PointerTo(t).NumMethod() > 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// structOf reports the panics of reflect.StructOf, e.g. on embedded types with
+// methods, as errors.
+func structOf(t reflect.Type, fields []reflect.StructField) (derived reflect.Type, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprintf(retagStructOfErr, t, r))
+		}
+	}()
+	return reflect.StructOf(fields), nil
+}
+
+// ConvertRetagged converts v to t, where either type was derived from the other
+// by Retag. The parts of v whose types were replaced by Retag are copied, the
+// other ones are converted with reflect.Value.Convert.
+func ConvertRetagged(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.Type().ConvertibleTo(t) {
+		return v.Convert(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		converted := reflect.New(t).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			converted.Field(i).Set(ConvertRetagged(v.Field(i), t.Field(i).Type))
+		}
+		return converted
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		converted := reflect.New(t.Elem())
+		converted.Elem().Set(ConvertRetagged(v.Elem(), t.Elem()))
+		return converted
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		converted := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			converted.Index(i).Set(ConvertRetagged(v.Index(i), t.Elem()))
+		}
+		return converted
+	case reflect.Array:
+		converted := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			converted.Index(i).Set(ConvertRetagged(v.Index(i), t.Elem()))
+		}
+		return converted
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		converted := reflect.MakeMapWithSize(t, v.Len())
+		for entries := v.MapRange(); entries.Next(); {
+			converted.SetMapIndex(ConvertRetagged(entries.Key(), t.Key()), ConvertRetagged(entries.Value(), t.Elem()))
+		}
+		return converted
+	default:
+		return v.Convert(t)
+	}
+}
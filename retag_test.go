@@ -0,0 +1,174 @@
+package fogg
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type retagAddress struct {
+	City string `json:"city"`
+}
+
+type retagNode struct {
+	Name string       `json:"name"`
+	Next *retagNode   `json:"next,omitempty"`
+	At   time.Time    `json:"at"`
+	Home retagAddress `json:"home"`
+}
+
+type retagUser struct {
+	ID      int    `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name,omitempty"`
+	Profile struct {
+		Bio string `json:"bio"`
+	}
+	Links []*struct {
+		URL string `json:"url"`
+	}
+	Address retagAddress `json:"address"`
+}
+
+type RetagMethods struct{}
+
+func (RetagMethods) Method() {}
+
+func TestRetag(t *testing.T) {
+	renames := map[string]string{"name": "full_name", "bio": "about", "url": "href", "address": "home", "city": "town"}
+
+	derived, err := Retag(reflect.TypeOf(retagUser{}), func(field reflect.StructField, s *Storage) error {
+		if !s.HasTag("json") {
+			return nil
+		}
+		json := s.GetTag("json")
+		if rename, exists := renames[json.GetValue()]; exists {
+			return s.Set("json", rename+json.GetContent()[len(json.GetValue()):])
+		}
+		s.Remove("gorm")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if tag := derived.Field(0).Tag; tag != `json:"id"` {
+		t.Errorf("got tag %q of ID", tag)
+	}
+	if tag := derived.Field(1).Tag; tag != `json:"full_name,omitempty"` {
+		t.Errorf("got tag %q of Name", tag)
+	}
+	if tag := derived.Field(4).Type.Field(0).Tag; derived.Field(4).Type.Name() != "" || tag != `json:"town"` {
+		t.Errorf("got type %s with tag %q of Address", derived.Field(4).Type, tag)
+	}
+
+	user := retagUser{ID: 1, Name: "Ann", Address: retagAddress{City: "Oslo"}}
+	user.Profile.Bio = "hi"
+	user.Links = []*struct {
+		URL string `json:"url"`
+	}{{URL: "https://example.com"}}
+
+	converted := ConvertRetagged(reflect.ValueOf(user), derived)
+	encoded, err := json.Marshal(converted.Interface())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const expected = `{"id":1,"full_name":"Ann","Profile":{"about":"hi"},"Links":[{"href":"https://example.com"}],"home":{"town":"Oslo"}}`
+	if string(encoded) != expected {
+		t.Errorf("got %s, expected %s", encoded, expected)
+	}
+
+	if back := ConvertRetagged(converted, reflect.TypeOf(user)).Interface(); !reflect.DeepEqual(back, user) {
+		t.Errorf("got %+v back, expected %+v", back, user)
+	}
+	if derived.Size() != reflect.TypeOf(user).Size() {
+		t.Errorf("got size %d, expected %d", derived.Size(), reflect.TypeOf(user).Size())
+	}
+}
+
+func TestRetagKeptTypes(t *testing.T) {
+	derived, err := Retag(reflect.TypeOf(retagNode{}), func(field reflect.StructField, s *Storage) error {
+		return s.Set("json", strings.ToUpper(s.GetTag("json").GetContent()))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if next := derived.Field(1).Type; next != reflect.TypeOf(&retagNode{}) {
+		t.Errorf("recursive type of Next was rewritten to %s", next)
+	}
+	if at := derived.Field(2).Type; at != reflect.TypeOf(time.Time{}) {
+		t.Errorf("type of At with methods was rewritten to %s", at)
+	}
+	if home := derived.Field(3).Type; home.Name() != "" || home.Field(0).Tag != `json:"CITY"` {
+		t.Errorf("got type %s of Home", home)
+	}
+
+	node := retagNode{Name: "a", Next: &retagNode{Name: "b"}, At: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Home: retagAddress{City: "Oslo"}}
+	encoded, err := json.Marshal(ConvertRetagged(reflect.ValueOf(node), derived).Interface())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const expected = `{"NAME":"a","NEXT":{"name":"b","at":"0001-01-01T00:00:00Z","home":{"city":""}},"AT":"2024-01-02T00:00:00Z","HOME":{"CITY":"Oslo"}}`
+	if string(encoded) != expected {
+		t.Errorf("got %s, expected %s", encoded, expected)
+	}
+}
+
+func TestRetagApostrophes(t *testing.T) {
+	type comment struct {
+		A int `gorm:"comment:it's" help:"user's name" json:"a"`
+		B int `env:"B" env:"C"`
+	}
+
+	derived, err := Retag(reflect.TypeOf(comment{}), func(field reflect.StructField, s *Storage) error {
+		return s.Set("json", "b")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tag := derived.Field(0).Tag; tag != `gorm:"comment:it's" help:"user's name" json:"b"` {
+		t.Errorf("got tag %q", tag)
+	}
+	if tag := derived.Field(1).Tag; tag != `env:"B" json:"b"` {
+		t.Errorf("got tag %q of the field with a repeated key", tag)
+	}
+}
+
+func TestRetagErrors(t *testing.T) {
+	keep := func(reflect.StructField, *Storage) error { return nil }
+
+	type unexported struct {
+		A int
+		b int
+	}
+	type embedded struct {
+		A int
+		RetagMethods
+	}
+	invalid := reflect.StructOf([]reflect.StructField{{Name: "A", Type: reflect.TypeOf(0), Tag: `json:"a",xml:"b"`}})
+
+	tests := []struct {
+		t        reflect.Type
+		expected string
+	}{
+		{reflect.TypeOf(0), `expected a struct type, got int`},
+		{reflect.TypeOf(unexported{}), `cannot retag fogg.unexported, its field b is unexported`},
+		{reflect.TypeOf(embedded{}), `cannot retag fogg.embedded: reflect: embedded type with methods not implemented if type is not first field`},
+		{invalid, `invalid tag of field A: key:"value" pairs not separated by spaces at offset 8`},
+	}
+
+	for _, test := range tests {
+		if _, err := Retag(test.t, keep); err == nil || err.Error() != test.expected {
+			t.Errorf("Retag(%s) error = %v; want %q", test.t, err, test.expected)
+		}
+	}
+
+	failure := errors.New("failure")
+	_, err := Retag(reflect.TypeOf(retagUser{}), func(reflect.StructField, *Storage) error { return failure })
+	if err != failure {
+		t.Errorf("got error %v, expected %v", err, failure)
+	}
+}
@@ -0,0 +1,91 @@
+package sqlmap
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// fakeResult is the result of a query run by the fake driver.
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDriver answers queries from results, keyed by the query text.
+type fakeDriver struct {
+	results map[string]fakeResult
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+type fakeStmt struct {
+	result fakeResult
+}
+
+type fakeRows struct {
+	result fakeResult
+	pos    int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	result, exists := c.driver.results[query]
+	if !exists {
+		return nil, errors.New("unknown query " + query)
+	}
+	return &fakeStmt{result: result}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.result}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.result.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+var fakeResults = map[string]fakeResult{}
+
+func init() {
+	sql.Register("sqlmap-fake", &fakeDriver{results: fakeResults})
+}
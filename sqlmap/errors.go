@@ -0,0 +1,9 @@
+package sqlmap
+
+const (
+	notStructErr        string = `expected a struct type, got %s`
+	notStructPointerErr string = `expected a pointer to a struct, got %s`
+	notSlicePointerErr  string = `expected a pointer to a slice of structs, got %s`
+	invalidTagErr       string = `invalid gorm tag of field %s: %s`
+	unknownColumnErr    string = `column "%s" has no field in %s`
+)
@@ -0,0 +1,90 @@
+package sqlmap
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The pluralization rules of jinzhu/inflection, which GORM uses for table
+// names. Later rules take precedence over earlier ones.
+var pluralRules = [][2]string{
+	{"([a-z])$", "${1}s"},
+	{"s$", "s"},
+	{"^(ax|test)is$", "${1}es"},
+	{"(octop|vir)us$", "${1}i"},
+	{"(octop|vir)i$", "${1}i"},
+	{"(alias|status|campus)$", "${1}es"},
+	{"(bu)s$", "${1}ses"},
+	{"(buffal|tomat)o$", "${1}oes"},
+	{"([ti])um$", "${1}a"},
+	{"([ti])a$", "${1}a"},
+	{"sis$", "ses"},
+	{"(?:([^f])fe|([lr])f)$", "${1}${2}ves"},
+	{"(hive)$", "${1}s"},
+	{"([^aeiouy]|qu)y$", "${1}ies"},
+	{"(x|ch|ss|sh)$", "${1}es"},
+	{"(matr|vert|ind)(?:ix|ex)$", "${1}ices"},
+	{"^(m|l)ouse$", "${1}ice"},
+	{"^(m|l)ice$", "${1}ice"},
+	{"^(ox)$", "${1}en"},
+	{"^(oxen)$", "${1}"},
+	{"(quiz)$", "${1}zes"},
+}
+
+var irregularPlurals = [][2]string{
+	{"person", "people"},
+	{"man", "men"},
+	{"child", "children"},
+	{"sex", "sexes"},
+	{"move", "moves"},
+	{"mombie", "mombies"},
+}
+
+var uncountables = []string{"equipment", "information", "rice", "money", "species", "series", "fish", "sheep", "jeans", "police"}
+
+type inflection struct {
+	regexp  *regexp.Regexp
+	replace string
+}
+
+var pluralInflections = compilePluralInflections()
+
+// compilePluralInflections orders the rules as jinzhu/inflection does:
+// uncountables, irregulars, then regular rules from the last one.
+func compilePluralInflections() []inflection {
+	var inflections []inflection
+	for _, word := range uncountables {
+		inflections = append(inflections, inflection{regexp.MustCompile("^(?i)(" + word + ")$"), "${1}"})
+	}
+	for _, irregular := range irregularPlurals {
+		singular, plural := irregular[0], irregular[1]
+		inflections = append(inflections,
+			inflection{regexp.MustCompile(strings.ToUpper(singular) + "$"), strings.ToUpper(plural)},
+			inflection{regexp.MustCompile(title(singular) + "$"), title(plural)},
+			inflection{regexp.MustCompile(singular + "$"), plural},
+		)
+	}
+	for i := len(pluralRules) - 1; i >= 0; i-- {
+		find, replace := pluralRules[i][0], pluralRules[i][1]
+		inflections = append(inflections,
+			inflection{regexp.MustCompile(strings.ToUpper(find)), strings.ToUpper(replace)},
+			inflection{regexp.MustCompile(find), replace},
+			inflection{regexp.MustCompile("(?i)" + find), replace},
+		)
+	}
+	return inflections
+}
+
+// plural returns the plural of an English word with the first matching rule.
+func plural(word string) string {
+	for _, inflection := range pluralInflections {
+		if inflection.regexp.MatchString(word) {
+			return inflection.regexp.ReplaceAllString(word, inflection.replace)
+		}
+	}
+	return word
+}
+
+func title(word string) string {
+	return strings.ToUpper(word[:1]) + word[1:]
+}
@@ -0,0 +1,114 @@
+package sqlmap
+
+import (
+	"strings"
+)
+
+// Namer names the table of a model and the column of a field without a
+// `column` param in its gorm tag. Its methods match the ones of GORM's
+// schema.Namer.
+type Namer interface {
+	TableName(name string) string
+	ColumnName(table, field string) string
+}
+
+// Replacer replaces parts of names before they are converted.
+type Replacer interface {
+	Replace(name string) string
+}
+
+// NamingStrategy names tables and columns with the rules of GORM's
+// schema.NamingStrategy: snake_case with common initialisms kept together,
+// plural table names unless SingularTable is set, and TablePrefix before table
+// names.
+type NamingStrategy struct {
+	TablePrefix   string
+	SingularTable bool
+	NameReplacer  Replacer
+	NoLowerCase   bool
+}
+
+// commonInitialisms are the ones of GORM, taken from golint.
+var commonInitialisms = []string{"API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SSH", "TLS", "TTL", "UID", "UI", "UUID", "URI", "URL", "UTF8", "VM", "XML", "XSRF", "XSS"}
+
+var commonInitialismsReplacer = newInitialismsReplacer()
+
+// newInitialismsReplacer replaces every initialism with its title case, so that
+// `HTTPServer` is converted as `HttpServer`.
+func newInitialismsReplacer() *strings.Replacer {
+	replacements := make([]string, 0, 2*len(commonInitialisms))
+	for _, initialism := range commonInitialisms {
+		replacements = append(replacements, initialism, initialism[:1]+strings.ToLower(initialism[1:]))
+	}
+	return strings.NewReplacer(replacements...)
+}
+
+// TableName returns the prefixed and, unless SingularTable is set, pluralized
+// snake_case name of a model type.
+func (ns NamingStrategy) TableName(name string) string {
+	if ns.SingularTable {
+		return ns.TablePrefix + ns.toDBName(name)
+	}
+	return ns.TablePrefix + plural(ns.toDBName(name))
+}
+
+// ColumnName returns the snake_case name of a field.
+func (ns NamingStrategy) ColumnName(_, field string) string {
+	return ns.toDBName(field)
+}
+
+// toDBName follows GORM byte for byte: capitals are lowered and preceded by an
+// underscore, except inside runs of capitals and digits.
+func (ns NamingStrategy) toDBName(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	if ns.NameReplacer != nil {
+		if replaced := ns.NameReplacer.Replace(name); replaced != "" {
+			name = replaced
+		}
+	}
+	if ns.NoLowerCase {
+		return name
+	}
+
+	var (
+		value              = commonInitialismsReplacer.Replace(name)
+		builder            strings.Builder
+		lastCase, nextCase bool
+		nextNumber         bool
+		currentCase        = isUpper(value[0])
+	)
+
+	for i, char := range value[:len(value)-1] {
+		nextCase = isUpper(value[i+1])
+		nextNumber = value[i+1] >= '0' && value[i+1] <= '9'
+
+		if currentCase {
+			if !(lastCase && (nextCase || nextNumber)) && i > 0 && value[i-1] != '_' && value[i+1] != '_' {
+				builder.WriteByte('_')
+			}
+			builder.WriteRune(char + 32)
+		} else {
+			builder.WriteRune(char)
+		}
+
+		lastCase = currentCase
+		currentCase = nextCase
+	}
+
+	if currentCase {
+		if !lastCase && len(value) > 1 {
+			builder.WriteByte('_')
+		}
+		builder.WriteByte(value[len(value)-1] + 32)
+	} else {
+		builder.WriteByte(value[len(value)-1])
+	}
+	return builder.String()
+}
+
+func isUpper(char byte) bool {
+	return char >= 'A' && char <= 'Z'
+}
@@ -0,0 +1,141 @@
+package sqlmap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"", ""},
+		{"x", "x"},
+		{"X", "x"},
+		{"userRestrictions", "user_restrictions"},
+		{"ThisIsATest", "this_is_a_test"},
+		{"PFAndESI", "pf_and_esi"},
+		{"AbcAndJkl", "abc_and_jkl"},
+		{"EmployeeID", "employee_id"},
+		{"SKU_ID", "sku_id"},
+		{"FieldX", "field_x"},
+		{"HTTPAndSMTP", "http_and_smtp"},
+		{"HTTPServerHandlerForURLID", "http_server_handler_for_url_id"},
+		{"UUID", "uuid"},
+		{"HTTPURL", "http_url"},
+		{"HTTP_URL", "http_url"},
+		{"SHA256Hash", "sha256_hash"},
+		{"SHA256HASH", "sha256_hash"},
+		{"CreatedAt", "created_at"},
+		{"MemberNumber", "member_number"},
+		{"ThisIsActuallyATestSoWeMayBeAbleToUseThisCodeInGormPackageAlsoIdCanBeUsedAtTheEndAsID", "this_is_actually_a_test_so_we_may_be_able_to_use_this_code_in_gorm_package_also_id_can_be_used_at_the_end_as_id"},
+	}
+
+	for _, test := range tests {
+		if name := (NamingStrategy{}).ColumnName("", test.field); name != test.expected {
+			t.Errorf("ColumnName(%q) = %q; want %q", test.field, name, test.expected)
+		}
+	}
+}
+
+func TestTableName(t *testing.T) {
+	tests := []struct {
+		strategy NamingStrategy
+		model    string
+		expected string
+	}{
+		{NamingStrategy{}, "User", "users"},
+		{NamingStrategy{}, "UserProfile", "user_profiles"},
+		{NamingStrategy{}, "Company", "companies"},
+		{NamingStrategy{}, "Address", "addresses"},
+		{NamingStrategy{}, "Status", "statuses"},
+		{NamingStrategy{}, "Person", "people"},
+		{NamingStrategy{}, "Salesperson", "salespeople"},
+		{NamingStrategy{}, "Child", "children"},
+		{NamingStrategy{}, "Fish", "fish"},
+		{NamingStrategy{}, "Equipment", "equipment"},
+		{NamingStrategy{}, "Box", "boxes"},
+		{NamingStrategy{}, "Match", "matches"},
+		{NamingStrategy{}, "Knife", "knives"},
+		{NamingStrategy{}, "Wolf", "wolves"},
+		{NamingStrategy{}, "Mouse", "mice"},
+		{NamingStrategy{}, "Matrix", "matrices"},
+		{NamingStrategy{}, "Index", "indices"},
+		{NamingStrategy{}, "Quiz", "quizzes"},
+		{NamingStrategy{}, "Day", "days"},
+		{NamingStrategy{}, "Analysis", "analyses"},
+		{NamingStrategy{}, "Datum", "data"},
+		{NamingStrategy{}, "APIKey", "api_keys"},
+		{NamingStrategy{TablePrefix: "t_"}, "User", "t_users"},
+		{NamingStrategy{SingularTable: true}, "User", "user"},
+		{NamingStrategy{TablePrefix: "public.", SingularTable: true}, "Company", "public.company"},
+		{NamingStrategy{NoLowerCase: true, SingularTable: true}, "UserProfile", "UserProfile"},
+	}
+
+	for _, test := range tests {
+		if name := test.strategy.TableName(test.model); name != test.expected {
+			t.Errorf("%+v.TableName(%q) = %q; want %q", test.strategy, test.model, name, test.expected)
+		}
+	}
+}
+
+func TestNameReplacer(t *testing.T) {
+	strategy := NamingStrategy{NameReplacer: strings.NewReplacer("CID", "Cid")}
+	if name := strategy.ColumnName("", "NameCID"); name != "name_cid" {
+		t.Errorf("ColumnName(%q) = %q; want %q", "NameCID", name, "name_cid")
+	}
+
+	strategy = NamingStrategy{NameReplacer: strings.NewReplacer("Name", ""), NoLowerCase: true}
+	if name := strategy.ColumnName("", "Name"); name != "Name" {
+		t.Errorf("ColumnName(%q) = %q; want %q", "Name", name, "Name")
+	}
+}
+
+type Contact struct {
+	Phone    string
+	EmailURL string
+}
+
+type Account struct {
+	ID           uint
+	UUID         string
+	OwnerName    string  `gorm:"column:owner"`
+	Contact      Contact `gorm:"embedded;embeddedPrefix:contact_"`
+	Backup       Contact `gorm:"embedded"`
+	HTTPEndpoint string
+	Ignored      string `gorm:"-"`
+}
+
+type Invoice struct {
+	Number string
+}
+
+func (Invoice) TableName() string {
+	return "billing_invoices"
+}
+
+func TestConformance(t *testing.T) {
+	mapper := NewMapper(NamingStrategy{TablePrefix: "app_"})
+	columns, err := mapper.Columns(reflect.TypeOf(Account{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	expected := []string{"id", "uuid", "owner", "contact_phone", "contact_email_url", "phone", "email_url", "http_endpoint"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("got %v, expected %v", names, expected)
+	}
+
+	if table := mapper.TableName(reflect.TypeOf(Account{})); table != "app_accounts" {
+		t.Errorf("got table %q, expected %q", table, "app_accounts")
+	}
+	if table := mapper.TableName(reflect.TypeOf(Invoice{})); table != "billing_invoices" {
+		t.Errorf("got table %q, expected %q", table, "billing_invoices")
+	}
+}
@@ -0,0 +1,260 @@
+// Package sqlmap scans database/sql rows into structs by column name, naming
+// columns after the gorm tags of their fields as GORM does.
+//
+// A field takes the column of its `column` param or else the name given by the
+// Namer of the mapper, GORM's default NamingStrategy for the package-level
+// functions. Fields tagged `-`, `-:all` or `->:false` are not read.
+// Anonymous struct fields and fields with the `embedded` option contribute
+// their own fields, with the columns prefixed by their `embeddedPrefix` param.
+package sqlmap
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/kuzgoga/fogg"
+)
+
+const (
+	tagName             = "gorm"
+	ignoreOption        = "-"
+	ignoreParam         = "-"
+	ignoreAllValue      = "all"
+	readParam           = "->"
+	readDisabledValue   = "false"
+	columnParam         = "column"
+	embeddedOption      = "embedded"
+	embeddedPrefixParam = "embeddedPrefix"
+)
+
+// Column is a column mapped to a field, found by Index as in
+// reflect.Value.FieldByIndex.
+type Column struct {
+	Name  string
+	Index []int
+}
+
+// Tabler is implemented by models naming their own table, as in GORM.
+type Tabler interface {
+	TableName() string
+}
+
+// Mapper maps columns to struct fields and caches the mapping of every type.
+type Mapper struct {
+	namer Namer
+	mutex sync.RWMutex
+	types map[reflect.Type][]Column
+}
+
+var defaultMapper = NewMapper(NamingStrategy{})
+
+func NewMapper(namer Namer) *Mapper {
+	return &Mapper{
+		namer: namer,
+		types: make(map[reflect.Type][]Column),
+	}
+}
+
+// Columns returns the columns of a struct type in field order.
+func (mapper *Mapper) Columns(structType reflect.Type) ([]Column, error) {
+	if structType.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf(notStructErr, structType))
+	}
+
+	mapper.mutex.RLock()
+	columns, exists := mapper.types[structType]
+	mapper.mutex.RUnlock()
+	if exists {
+		return columns, nil
+	}
+
+	columns, err := mapper.structColumns(structType, mapper.TableName(structType), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	mapper.mutex.Lock()
+	mapper.types[structType] = columns
+	mapper.mutex.Unlock()
+	return columns, nil
+}
+
+// TableName returns the table of a struct type, named by its TableName method
+// or else by the Namer of the mapper.
+func (mapper *Mapper) TableName(structType reflect.Type) string {
+	if tabler, ok := reflect.New(structType).Interface().(Tabler); ok {
+		return tabler.TableName()
+	}
+	return mapper.namer.TableName(structType.Name())
+}
+
+func (mapper *Mapper) structColumns(structType reflect.Type, table string, index []int, prefix string) ([]Column, error) {
+	columns := []Column{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// GORM ignores the case of keys
+		tag, err := fogg.ParseSubtag(field.Tag.Get(tagName), true, fogg.WithNormalizer(fogg.FoldCase))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(invalidTagErr, field.Name, err))
+		}
+		if !readable(&tag) {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct && (field.Anonymous || tag.HasOption(embeddedOption)) {
+			embedded, err := mapper.structColumns(fieldType, table, fieldIndex, prefix+tag.GetParamOr(embeddedPrefixParam, ""))
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, embedded...)
+			continue
+		}
+
+		name := tag.GetParamOr(columnParam, "")
+		if name == "" {
+			name = mapper.namer.ColumnName(table, field.Name)
+		}
+		columns = append(columns, Column{Name: prefix + name, Index: fieldIndex})
+	}
+	return columns, nil
+}
+
+// readable reports whether GORM reads the field of the tag from the database.
+func readable(tag *fogg.Tag) bool {
+	if tag.HasOption(ignoreOption) {
+		return false
+	}
+	if value := tag.GetParamOr(ignoreParam, ""); strings.EqualFold(value, ignoreAllValue) {
+		return false
+	}
+	return !strings.EqualFold(tag.GetParamOr(readParam, ""), readDisabledValue)
+}
+
+// ScanRow scans the current row of rows into the struct dest points to with the
+// default mapper.
+func ScanRow(rows *sql.Rows, dest any) error {
+	return defaultMapper.ScanRow(rows, dest)
+}
+
+// ScanAll scans every row of rows into the slice dest points to with the
+// default mapper.
+func ScanAll(rows *sql.Rows, dest any) error {
+	return defaultMapper.ScanAll(rows, dest)
+}
+
+// ScanRow scans the current row of rows into the struct dest points to. Every
+// column of the row must map to a field.
+func (mapper *Mapper) ScanRow(rows *sql.Rows, dest any) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf(notStructPointerErr, reflect.TypeOf(dest)))
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes, err := mapper.indexes(value.Elem().Type(), names)
+	if err != nil {
+		return err
+	}
+	return rows.Scan(targets(value.Elem(), indexes)...)
+}
+
+// ScanAll scans every row of rows into the slice dest points to, which holds
+// structs or pointers to structs, and closes rows.
+func (mapper *Mapper) ScanAll(rows *sql.Rows, dest any) error {
+	defer rows.Close()
+
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf(notSlicePointerErr, reflect.TypeOf(dest)))
+	}
+	slice := value.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf(notSlicePointerErr, reflect.TypeOf(dest)))
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes, err := mapper.indexes(structType, names)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		row := reflect.New(structType)
+		if err := rows.Scan(targets(row.Elem(), indexes)...); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Pointer {
+			slice.Set(reflect.Append(slice, row))
+		} else {
+			slice.Set(reflect.Append(slice, row.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// indexes finds the field of every column name.
+func (mapper *Mapper) indexes(structType reflect.Type, names []string) ([][]int, error) {
+	columns, err := mapper.Columns(structType)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([][]int, len(names))
+	for i, name := range names {
+		for _, column := range columns {
+			if column.Name == name {
+				indexes[i] = column.Index
+				break
+			}
+		}
+		if indexes[i] == nil {
+			return nil, errors.New(fmt.Sprintf(unknownColumnErr, name, structType))
+		}
+	}
+	return indexes, nil
+}
+
+// targets returns pointers to the fields at indexes, allocating the embedded
+// structs they go through.
+func targets(value reflect.Value, indexes [][]int) []any {
+	pointers := make([]any, len(indexes))
+	for i, index := range indexes {
+		field := value
+		for _, position := range index {
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			field = field.Field(position)
+		}
+		pointers[i] = field.Addr().Interface()
+	}
+	return pointers
+}
@@ -0,0 +1,162 @@
+package sqlmap
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Model struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+type author struct {
+	Name  string
+	Email string
+}
+
+type blog struct {
+	Model
+	Title    string  `gorm:"column:blog_title;size:256"`
+	Author   author  `gorm:"embedded;embeddedPrefix:author_"`
+	Editor   *author `gorm:"EMBEDDED;EMBEDDEDPREFIX:editor_"`
+	Upvotes  int32   `gorm:"default:0"`
+	Secret   string  `gorm:"-"`
+	Hidden   string  `gorm:"-:all"`
+	Computed string  `gorm:"->:false;<-:create"`
+	Migrated string  `gorm:"-:migration"`
+	Rating   float64 `gorm:"COLUMN:score"`
+	internal string
+}
+
+func TestColumns(t *testing.T) {
+	columns, err := NewMapper(NamingStrategy{}).Columns(reflect.TypeOf(blog{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Column{
+		{"id", []int{0, 0}},
+		{"created_at", []int{0, 1}},
+		{"blog_title", []int{1}},
+		{"author_name", []int{2, 0}},
+		{"author_email", []int{2, 1}},
+		{"editor_name", []int{3, 0}},
+		{"editor_email", []int{3, 1}},
+		{"upvotes", []int{4}},
+		{"migrated", []int{8}},
+		{"score", []int{9}},
+	}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("got %v, expected %v", columns, expected)
+	}
+
+	if _, err := NewMapper(NamingStrategy{}).Columns(reflect.TypeOf(0)); err == nil || err.Error() != `expected a struct type, got int` {
+		t.Errorf("unexpected error %v", err)
+	}
+	type invalid struct {
+		A int `gorm:"column:'a"`
+	}
+	if _, err := NewMapper(NamingStrategy{}).Columns(reflect.TypeOf(invalid{})); err == nil || err.Error() != `invalid gorm tag of field A: unclosed backtick in tag` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func openFake(t *testing.T, query string, result fakeResult) *sql.Rows {
+	t.Helper()
+	fakeResults[query] = result
+
+	db, err := sql.Open("sqlmap-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestScanAll(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := openFake(t, "blogs", fakeResult{
+		columns: []string{"id", "blog_title", "author_name", "editor_email", "created_at", "score"},
+		rows: [][]driver.Value{
+			{int64(1), "First", "Ann", "bob@example.com", created, 4.5},
+			{int64(2), "Second", "Cid", "", created, 3.0},
+		},
+	})
+
+	var blogs []blog
+	if err := ScanAll(rows, &blogs); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []blog{
+		{Model: Model{ID: 1, CreatedAt: created}, Title: "First", Author: author{Name: "Ann"}, Editor: &author{Email: "bob@example.com"}, Rating: 4.5},
+		{Model: Model{ID: 2, CreatedAt: created}, Title: "Second", Author: author{Name: "Cid"}, Editor: &author{}, Rating: 3},
+	}
+	if !reflect.DeepEqual(blogs, expected) {
+		t.Errorf("got %+v, expected %+v", blogs, expected)
+	}
+}
+
+func TestScanAllPointers(t *testing.T) {
+	rows := openFake(t, "authors", fakeResult{
+		columns: []string{"name", "email"},
+		rows:    [][]driver.Value{{"Ann", "ann@example.com"}},
+	})
+
+	var authors []*author
+	if err := ScanAll(rows, &authors); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(authors) != 1 || *authors[0] != (author{Name: "Ann", Email: "ann@example.com"}) {
+		t.Errorf("unexpected authors %+v", authors)
+	}
+}
+
+func TestScanRow(t *testing.T) {
+	rows := openFake(t, "author", fakeResult{
+		columns: []string{"email"},
+		rows:    [][]driver.Value{{"ann@example.com"}},
+	})
+	defer rows.Close()
+
+	var scanned author
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	if err := ScanRow(rows, &scanned); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if scanned.Email != "ann@example.com" {
+		t.Errorf("unexpected author %+v", scanned)
+	}
+
+	if err := ScanRow(rows, scanned); err == nil || err.Error() != `expected a pointer to a struct, got sqlmap.author` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestScanUnknownColumn(t *testing.T) {
+	rows := openFake(t, "secrets", fakeResult{
+		columns: []string{"name", "secret"},
+		rows:    [][]driver.Value{{"Ann", "x"}},
+	})
+
+	var blogs []blog
+	if err := ScanAll(rows, &blogs); err == nil || err.Error() != `column "name" has no field in sqlmap.blog` {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	var numbers []int
+	if err := ScanAll(rows, &numbers); err == nil || err.Error() != `expected a pointer to a slice of structs, got *[]int` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -3,47 +3,104 @@ package fogg
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 type Storage struct {
-	tags map[string]Tag
+	tags      map[string]Tag
+	names     []string
+	warnings  []Warning
+	goEscapes bool
+	dialects  bool
 }
 
-func Parse(tagContent string) (Storage, error) {
+// Parse parses the tags of a struct tag, all of them in the GORM style unless
+// WithDialects is given.
+func Parse(tagContent string, options ...ParseOption) (Storage, error) {
+	config := newParseConfig(options)
+
 	backticks := []string{`"`}
 	delimiters := []string{" "}
 
 	const nameValueDelimiter = ":"
 
 	storage := Storage{
-		tags: make(map[string]Tag),
+		tags:      make(map[string]Tag),
+		goEscapes: config.goEscapes,
+		dialects:  config.dialects,
+	}
+
+	if err := checkLimit("MaxLength", config.limits.MaxLength, len(tagContent)); err != nil {
+		return storage, err
 	}
 
-	splitTags, err := splitTagItems(tagContent, true, backticks, delimiters, false)
+	var (
+		splitTags []string
+		spans     []Span
+		err       error
+	)
+	switch {
+	case config.syntax == syntaxLenient:
+		splitTags, storage.warnings, err = splitLenientTags(tagContent)
+	case config.syntax == syntaxStrict:
+		splitTags, spans, err = splitStrictTags(tagContent)
+	case config.goEscapes:
+		splitTags, err = splitGoTags(tagContent)
+	default:
+		splitTags, err = splitTagItems(tagContent, true, backticks, delimiters, false)
+	}
 	if err != nil {
 		return storage, err
 	}
+	if err := checkLimit("MaxTags", config.limits.MaxTags, len(splitTags)); err != nil {
+		return storage, err
+	}
 
-	for _, t := range splitTags {
-		pair := strings.SplitN(t, nameValueDelimiter, 2)
-		name, value := pair[0], pair[1]
+	for i, t := range splitTags {
+		if err := config.canceled(); err != nil {
+			return storage, err
+		}
+		name, value, _ := strings.Cut(t, nameValueDelimiter)
 
-		value, err = unquoteTagContent(name, value)
+		switch {
+		case config.syntax == syntaxStrict:
+			// Values are checked to be valid literals by splitStrictTags
+			value, _ = strconv.Unquote(value)
+		case config.goEscapes:
+			value, err = unquoteGoTagContent(name, value)
+		default:
+			value, err = unquoteTagContent(name, value)
+		}
 		if err != nil {
 			return storage, err
 		}
 
-		tag, err := ParseSubtag(value, true)
+		tag, err := parseDialectSubtag(name, value, config)
+		if err != nil && config.syntax == syntaxStrict && !errors.Is(err, ErrLimitExceeded) {
+			// reflect.StructTag reads any tag, whatever its content
+			storage.warnings = append(storage.warnings, Warning{spans[i], fmt.Sprintf(unparsedContentWarn, name, err)})
+			tag, err = unparsedTag(value, config), nil
+		}
 		if err != nil {
 			return storage, err
 		}
 		tag.name = name
 
-		if _, exists := storage.tags[name]; !exists {
+		if existing, exists := storage.tags[name]; !exists {
 			storage.tags[name] = tag
+			storage.names = append(storage.names, name)
 		} else {
-			return storage, errors.New(fmt.Sprintf(duplicatedTagsErr, name))
+			switch config.duplicates {
+			case DuplicatesError:
+				return storage, errors.New(fmt.Sprintf(duplicatedTagsErr, name))
+			case DuplicatesLast:
+				storage.tags[name] = tag
+			case DuplicatesCollect:
+				storage.tags[name] = collectTag(existing, tag)
+			}
 		}
 	}
 
@@ -65,3 +122,135 @@ func (storage *Storage) HasTag(name string) bool {
 		return false
 	}
 }
+
+// unparsedTag keeps content as a tag without options and params.
+func unparsedTag(content string, config *parseConfig) Tag {
+	return Tag{
+		params:     make(map[string]TagParam),
+		options:    make([]string, 0),
+		rawOptions: make([]string, 0),
+		content:    content,
+		normalize:  config.normalize,
+	}
+}
+
+// collectTag merges a repeated tag into its first occurrence, keeping every
+// occurrence of its params.
+func collectTag(tag, other Tag) Tag {
+	collected := Tag{
+		name:       tag.name,
+		value:      tag.value,
+		params:     maps.Clone(tag.params),
+		options:    slices.Clone(tag.options),
+		rawOptions: slices.Clone(tag.rawOptions),
+		normalize:  tag.normalize,
+		dialect:    tag.dialect,
+	}
+	collected.index()
+	if collected.value == "" {
+		collected.value = other.value
+	}
+	for name, params := range tag.duplicates {
+		collected.addDuplicates(name, params...)
+	}
+
+	for i, option := range other.options {
+		if !collected.HasOption(option) {
+			collected.options = append(collected.options, option)
+			collected.rawOptions = append(collected.rawOptions, other.rawOptions[i])
+		}
+	}
+	for _, name := range sortedParamNames(&other) {
+		if key, exists := collected.paramKey(name); exists {
+			collected.addDuplicates(key, other.GetParamAll(name)...)
+		} else {
+			collected.setParam(name, other.params[name])
+			collected.addDuplicates(name, other.duplicates[name]...)
+		}
+	}
+
+	collected.content = formatDialectSubtag(&collected)
+	return collected
+}
+
+// Warnings returns the deviations from the tag syntax accepted by WithLenient,
+// and the tags kept unparsed by WithStrict, in the order they were found.
+func (storage *Storage) Warnings() []Warning {
+	return storage.warnings
+}
+
+// Names returns the names of the tags in the order they were written.
+func (storage *Storage) Names() []string {
+	return storage.names
+}
+
+// Set parses content as the tag name and puts it in place of the tag with the
+// same name, or after the other tags. content is unescaped if storage was parsed
+// with WithGoEscapes or WithStrict.
+func (storage *Storage) Set(name, content string) error {
+	if name == "" || strings.ContainsAny(name, " :\"") {
+		return errors.New(fmt.Sprintf(invalidTagNameErr, name))
+	}
+
+	var options []ParseOption
+	if storage.dialects {
+		options = append(options, WithDialects())
+	}
+
+	var (
+		parsed Storage
+		err    error
+	)
+	if storage.goEscapes {
+		parsed, err = Parse(name+":"+strconv.Quote(content), append(options, WithGoEscapes())...)
+	} else {
+		parsed, err = Parse(name+`:"`+content+`"`, options...)
+	}
+	if err != nil {
+		return err
+	}
+	if len(parsed.names) != 1 {
+		return errors.New(fmt.Sprintf(invalidTagContentErr, name))
+	}
+
+	if storage.tags == nil {
+		storage.tags = make(map[string]Tag)
+	}
+	if _, exists := storage.tags[name]; !exists {
+		storage.names = append(storage.names, name)
+	}
+	storage.tags[name] = parsed.tags[name]
+	return nil
+}
+
+// Remove deletes the tag name and reports whether it existed.
+func (storage *Storage) Remove(name string) bool {
+	if _, exists := storage.tags[name]; !exists {
+		return false
+	}
+
+	delete(storage.tags, name)
+	storage.names = slices.DeleteFunc(slices.Clone(storage.names), func(other string) bool {
+		return other == name
+	})
+	return true
+}
+
+// String writes the tags back in struct tag syntax, each from its content. The
+// contents are written as Go string literals if storage was parsed with
+// WithGoEscapes or WithStrict.
+func (storage *Storage) String() string {
+	var builder strings.Builder
+	for i, name := range storage.names {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		tag := storage.tags[name]
+		if storage.goEscapes {
+			builder.WriteString(name + ":" + strconv.Quote(tag.content))
+		} else {
+			builder.WriteString(name + `:"` + tag.content + `"`)
+		}
+	}
+	return builder.String()
+}
@@ -16,21 +16,29 @@ func TestParse(t *testing.T) {
 				Name:  "default",
 				Value: `ui\path`,
 				Args:  []string{`ui\path`},
+				Raw:   `'ui\\path'`,
+				Quote: QuoteSingle,
 			},
 			"foreignKey": {
 				Name:  "foreignKey",
 				Value: `Customer"Id`,
 				Args:  []string{`Customer"Id`},
+				Raw:   `Customer\"Id`,
 			},
 			"index": {
 				Name:  "index",
 				Value: `,unique`,
 				Args:  []string{``, `unique`},
+				Raw:   `,unique`,
 			},
 		},
 		options: []string{
 			"not null",
 		},
+		rawOptions: []string{
+			"not null",
+		},
+		content: `default:'ui\\path';index:,unique;not null;foreignKey:Customer\"Id`,
 	}
 
 	storage, err := Parse(tag)
@@ -105,3 +113,104 @@ func TestHasTagNotFound(t *testing.T) {
 		t.Errorf("expected false, got true")
 	}
 }
+
+func TestParseTagWithoutValue(t *testing.T) {
+	expectedError := fmt.Sprintf(valueLessTagErr, "gorm")
+	_, err := Parse(`json:"name" gorm`)
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expected error `%s`, got: %s", expectedError, err)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	const tag = `json:"customer_id" gorm:"column:customer_id;type:varchar(100);not null;default:'a\\'b';index:idx_customer,unique" ui:"label:Покупатель; readonly"`
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Parse(tag)
+	}
+}
+
+func TestParseJSONTag(t *testing.T) {
+	storage, err := Parse(`json:"it's,omitempty,string" gorm:"column:id"`, WithDialects())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tag := storage.GetTag("json")
+	if tag.GetValue() != "it's" {
+		t.Errorf("expected value `it's`, got `%s`", tag.GetValue())
+	}
+	if !reflect.DeepEqual(tag.GetOptions(), []string{"omitempty", "string"}) {
+		t.Errorf("unexpected options %v", tag.GetOptions())
+	}
+	if tag.GetContent() != "it's,omitempty,string" {
+		t.Errorf("unexpected content `%s`", tag.GetContent())
+	}
+	if field := ParseJSONField(tag.GetContent()); !field.OmitEmpty || !field.String {
+		t.Errorf("unexpected json field %+v", field)
+	}
+	if storage.GetTag("gorm").GetParamOr("column", "") != "id" {
+		t.Errorf("expected gorm tag to keep the GORM style")
+	}
+
+	storage, err = Parse(`json:"name,omitempty" validate:"required,min=1" protobuf:"bytes,1,opt"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, name := range storage.Names() {
+		tag := storage.GetTag(name)
+		if tag.GetValue() != tag.GetContent() || !reflect.DeepEqual(tag.GetOptions(), []string{tag.GetContent()}) {
+			t.Errorf("expected %s tag in the GORM style without WithDialects, got %+v", name, tag)
+		}
+	}
+}
+
+func TestStorageSet(t *testing.T) {
+	storage, err := Parse(`json:"name" gorm:"column:name" ui:"label:'Name'"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := storage.Set("gorm", "column:full_name;not null"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := storage.Set("xml", "name,attr"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !storage.Remove("ui") || storage.Remove("ui") {
+		t.Errorf("expected ui to be removed once")
+	}
+
+	if names := storage.Names(); !reflect.DeepEqual(names, []string{"json", "gorm", "xml"}) {
+		t.Errorf("got names %v", names)
+	}
+	if param := storage.GetTag("gorm").GetParam("column"); param == nil || param.Value != "full_name" {
+		t.Errorf("got column param %+v", param)
+	}
+	const expected = `json:"name" gorm:"column:full_name;not null" xml:"name,attr"`
+	if tag := storage.String(); tag != expected {
+		t.Errorf("got %q, expected %q", tag, expected)
+	}
+
+	invalid := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"gorm", "default:'a", unclosedBacktickErr},
+		{"two words", "a", `invalid tag name "two words"`},
+		{"", "a", `invalid tag name ""`},
+		{"gorm", `a" json:"b`, `content of "gorm" tag must not close its quotes`},
+	}
+	for _, test := range invalid {
+		if err := storage.Set(test.name, test.content); err == nil || err.Error() != test.expected {
+			t.Errorf("Set(%q, %q) error = %v; want %q", test.name, test.content, err, test.expected)
+		}
+	}
+
+	var empty Storage
+	if err := empty.Set("json", "id"); err != nil || empty.String() != `json:"id"` {
+		t.Errorf("got %q, %v", empty.String(), err)
+	}
+}
@@ -0,0 +1,106 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tagsWithCheckedSpaces are the tags whose values go vet checks for spaces.
+var tagsWithCheckedSpaces = map[string]bool{"json": true, "xml": true, "asn1": true}
+
+// splitStrictTags splits content with the grammar of reflect.StructTag and the
+// checks of the structtag analyzer of go vet. Tags are returned as written, as
+// `name:"value"` with a valid Go string literal, together with their spans.
+func splitStrictTags(content string) ([]string, []Span, error) {
+	var (
+		tags  []string
+		spans []Span
+	)
+
+	pos := 0
+	for pos < len(content) {
+		if len(tags) > 0 && content[pos] != tagDelimiter {
+			return nil, nil, errors.New(fmt.Sprintf(strictSpaceErr, pos))
+		}
+		for pos < len(content) && content[pos] == tagDelimiter {
+			pos++
+		}
+		if pos == len(content) {
+			break
+		}
+
+		start := pos
+		for pos < len(content) && isTagKeyChar(content[pos]) {
+			pos++
+		}
+		if pos == start {
+			return nil, nil, errors.New(fmt.Sprintf(strictKeyErr, pos))
+		}
+		if pos+1 >= len(content) || content[pos] != tagNameDelimiter {
+			return nil, nil, errors.New(fmt.Sprintf(strictPairErr, start))
+		}
+		if content[pos+1] != tagBacktick {
+			return nil, nil, errors.New(fmt.Sprintf(strictValueErr, pos+1))
+		}
+		name := content[start:pos]
+
+		valueStart := pos + 1
+		for pos = valueStart + 1; pos < len(content) && content[pos] != tagBacktick; pos++ {
+			if content[pos] == escapeBackslash {
+				pos++
+			}
+		}
+		if pos >= len(content) {
+			return nil, nil, errors.New(fmt.Sprintf(strictValueErr, valueStart))
+		}
+		pos++
+
+		value, err := strconv.Unquote(content[valueStart:pos])
+		if err != nil {
+			return nil, nil, errors.New(fmt.Sprintf(strictValueErr, valueStart))
+		}
+		if tagsWithCheckedSpaces[name] && hasSuspiciousSpace(name, value) {
+			return nil, nil, errors.New(fmt.Sprintf(strictValueSpaceErr, valueStart))
+		}
+		tags = append(tags, content[start:pos])
+		spans = append(spans, Span{start, pos})
+	}
+	return tags, spans, nil
+}
+
+// isTagKeyChar follows reflect.StructTag: keys are made of non-control
+// characters other than space, quote and colon.
+func isTagKeyChar(char byte) bool {
+	return char > ' ' && char != tagNameDelimiter && char != tagBacktick && char != 0x7f
+}
+
+// hasSuspiciousSpace follows go vet: names of json tags may contain spaces,
+// names of xml tags may hold a namespace and a single space, options never
+// contain spaces.
+func hasSuspiciousSpace(name, value string) bool {
+	const optionsDelimiter = ","
+
+	switch name {
+	case "xml":
+		if strings.Trim(value, " ") != value || strings.Count(value, " ") > 1 {
+			return true
+		}
+		comma := strings.Index(value, optionsDelimiter)
+		if comma < 0 {
+			return false
+		}
+		if comma > 0 && value[comma-1] == ' ' {
+			return true
+		}
+		value = value[comma+1:]
+	case "json":
+		comma := strings.Index(value, optionsDelimiter)
+		if comma < 0 {
+			return false
+		}
+		value = value[comma+1:]
+	}
+	return strings.Contains(value, " ")
+}
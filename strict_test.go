@@ -0,0 +1,213 @@
+package fogg
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected map[string]string
+	}{
+		{``, map[string]string{}},
+		{`json:"id"`, map[string]string{"json": "id"}},
+		{`  json:"id"   xml:"name,attr"  `, map[string]string{"json": "id", "xml": "name,attr"}},
+		{`json:"my name,omitempty"`, map[string]string{"json": "my name,omitempty"}},
+		{`xml:"urn:x name"`, map[string]string{"xml": "urn:x name"}},
+		{`env:"A\tB" note:"\u00e9\x41\"q\""`, map[string]string{"env": "A\tB", "note": "éA\"q\""}},
+		{`gorm:"default:'a b';not null"`, map[string]string{"gorm": "default:'a b';not null"}},
+		{`b:"q\"r" gorm:"default:\"x\""`, map[string]string{"b": `q"r`, "gorm": `default:"x"`}},
+		{`json:"a" json:"b"`, map[string]string{"json": "a"}},
+		{`gorm:"default:'a" validate:"required,,min=1"`, map[string]string{"gorm": "default:'a", "validate": "required,,min=1"}},
+	}
+
+	for _, test := range tests {
+		storage, err := Parse(test.tag, WithStrict())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.tag, err)
+			continue
+		}
+		contents := make(map[string]string)
+		for _, name := range storage.Names() {
+			contents[name] = storage.GetTag(name).content
+		}
+		if !reflect.DeepEqual(contents, test.expected) {
+			t.Errorf("%q: got %v, expected %v", test.tag, contents, test.expected)
+		}
+	}
+}
+
+func TestParseStrictWarnings(t *testing.T) {
+	storage, err := Parse(`json:"id" gorm:"default:'a;size:8" env:":x"`, WithStrict())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Warning{
+		{Span{10, 34}, "`gorm` tag content is kept unparsed: unclosed backtick in tag"},
+		{Span{35, 43}, "`env` tag content is kept unparsed: invalid param with empty name and value \"x\""},
+	}
+	if !reflect.DeepEqual(storage.Warnings(), expected) {
+		t.Errorf("got warnings %+v, expected %+v", storage.Warnings(), expected)
+	}
+	if gorm := storage.GetTag("gorm"); gorm.GetContent() != "default:'a;size:8" || len(gorm.GetParams()) != 0 {
+		t.Errorf("unexpected gorm tag %+v", gorm)
+	}
+	if written := storage.String(); written != `json:"id" gorm:"default:'a;size:8" env:":x"` {
+		t.Errorf("written as %q", written)
+	}
+
+	if _, err := Parse(`gorm:"a:1;b:2"`, WithStrict(), WithLimits(Limits{MaxParams: 1})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got error %v, expected MaxParams to be exceeded", err)
+	}
+}
+
+func TestParseStrictErrors(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`json:"a",xml:"b"`, `key:"value" pairs not separated by spaces at offset 8`},
+		{`json:"a"xml:"b"`, `key:"value" pairs not separated by spaces at offset 8`},
+		{`:"a"`, `bad syntax for struct tag key at offset 0`},
+		{"json:\"a\"\txml:\"b\"", `key:"value" pairs not separated by spaces at offset 8`},
+		{`json`, `bad syntax for struct tag pair at offset 0`},
+		{`json:`, `bad syntax for struct tag pair at offset 0`},
+		{`json:a`, `bad syntax for struct tag value at offset 5`},
+		{`json:"a`, `bad syntax for struct tag value at offset 5`},
+		{`json:"\q"`, `bad syntax for struct tag value at offset 5`},
+		{`env:"` + "a\nb" + `"`, `bad syntax for struct tag value at offset 4`},
+		{`json:"a,omitempty string"`, `suspicious space in struct tag value at offset 5`},
+		{`xml:" a"`, `suspicious space in struct tag value at offset 4`},
+		{`xml:"a b c"`, `suspicious space in struct tag value at offset 4`},
+		{`xml:"a ,attr"`, `suspicious space in struct tag value at offset 4`},
+		{`asn1:"a b"`, `suspicious space in struct tag value at offset 5`},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(test.tag, WithStrict()); err == nil || err.Error() != test.expected {
+			t.Errorf("%q: got error %v, expected %q", test.tag, err, test.expected)
+		}
+	}
+}
+
+// vetValidTag is the check of struct tags by the structtag analyzer of go vet.
+func vetValidTag(tag string) bool {
+	for n := 0; tag != ""; n++ {
+		if n > 0 && tag[0] != ' ' {
+			return false
+		}
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return false
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return false
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return false
+		}
+		tag = tag[i+1:]
+
+		switch key {
+		case "xml":
+			if strings.Trim(value, " ") != value || strings.Count(value, " ") > 1 {
+				return false
+			}
+			comma := strings.IndexRune(value, ',')
+			if comma < 0 {
+				continue
+			}
+			if comma > 0 && value[comma-1] == ' ' {
+				return false
+			}
+			value = value[comma+1:]
+		case "json":
+			comma := strings.IndexRune(value, ',')
+			if comma < 0 {
+				continue
+			}
+			value = value[comma+1:]
+		case "asn1":
+		default:
+			continue
+		}
+		if strings.IndexByte(value, ' ') >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzStrict(f *testing.F) {
+	seeds := []string{
+		``,
+		`json:"id"`,
+		`json:"id,omitempty" xml:"id,attr"`,
+		`  gorm:"default:'a b'"  `,
+		`json:"a" json:"b"`,
+		`b:"q\"r"`,
+		`gorm:"default:'a" env:":x"`,
+		`gorm:"default:\"x\";a:\\"`,
+		`json:"a",xml:"b"`,
+		`env:"\u00e9\t\"" note:"\\"`,
+		`json:"\q"`,
+		`xml:"urn:x name,attr"`,
+		`asn1:"a b"`,
+		"a\x7f:\"b\"",
+		"\xff:\"\xfe\"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		storage, err := Parse(content, WithStrict())
+		if valid := vetValidTag(content); (err == nil) != valid {
+			t.Fatalf("%q: got error %v, go vet reports it valid: %v", content, err, valid)
+		}
+		if err != nil {
+			return
+		}
+
+		written := storage.String()
+		reparsed, err := Parse(written, WithStrict())
+		if err != nil {
+			t.Fatalf("%q: written as %q which fails to parse: %s", content, written, err)
+		}
+		for _, name := range storage.Names() {
+			value := storage.GetTag(name).GetContent()
+			if expected, ok := reflect.StructTag(content).Lookup(name); !ok || value != expected {
+				t.Fatalf("%q: got %q for %q, reflect.StructTag.Lookup gives %q, %v", content, value, name, expected, ok)
+			}
+			if rewritten := reparsed.GetTag(name).GetContent(); rewritten != value {
+				t.Fatalf("%q: got %q for %q after writing it as %q", content, rewritten, name, written)
+			}
+		}
+	})
+}
@@ -5,10 +5,19 @@ import (
 )
 
 type Tag struct {
-	name    string
-	value   string
-	params  map[string]TagParam
-	options []string
+	name       string
+	value      string
+	params     map[string]TagParam
+	options    []string
+	rawOptions []string
+	content    string
+	// duplicates holds the occurrences of params after the first one
+	duplicates map[string][]TagParam
+	normalize  Normalizer
+	// keys maps the normalized keys of params to their keys
+	keys map[string]string
+	// dialect is set on tags parsed in the style of their dialect
+	dialect bool
 }
 
 func (tag *Tag) Name() string {
@@ -18,13 +27,18 @@ func (tag *Tag) Name() string {
 func (tag *Tag) HasOption(name string) bool {
 	if slices.Contains(tag.options, name) {
 		return true
+	} else if tag.normalize != nil {
+		normalized := tag.normalize(name)
+		return slices.ContainsFunc(tag.options, func(option string) bool {
+			return tag.normalize(option) == normalized
+		})
 	} else {
 		return false
 	}
 }
 
 func (tag *Tag) HasParam(name string) bool {
-	if _, exist := tag.params[name]; exist {
+	if _, exist := tag.paramKey(name); exist {
 		return true
 	} else {
 		return false
@@ -32,16 +46,27 @@ func (tag *Tag) HasParam(name string) bool {
 }
 
 func (tag *Tag) GetParam(name string) *TagParam {
-	if param, exist := tag.params[name]; exist {
+	if key, exist := tag.paramKey(name); exist {
+		param := tag.params[key]
 		return &param
 	} else {
 		return nil
 	}
 }
 
+// GetParamAll returns every occurrence of the param name in order. Repeated
+// params are only kept when parsing with DuplicatesCollect.
+func (tag *Tag) GetParamAll(name string) []TagParam {
+	if key, exist := tag.paramKey(name); exist {
+		return append([]TagParam{tag.params[key]}, tag.duplicates[key]...)
+	} else {
+		return nil
+	}
+}
+
 func (tag *Tag) GetParamOr(name string, defaultValue string) string {
-	if param, exist := tag.params[name]; exist {
-		return param.Value
+	if key, exist := tag.paramKey(name); exist {
+		return tag.params[key].Value
 	} else {
 		return defaultValue
 	}
@@ -55,6 +80,55 @@ func (tag *Tag) GetOptions() []string {
 	return tag.options
 }
 
+// GetRawOptions returns the options as written in the tag, in the same order as
+// GetOptions.
+func (tag *Tag) GetRawOptions() []string {
+	return tag.rawOptions
+}
+
+// GetContent returns the content of the tag as written between its quotes.
+func (tag *Tag) GetContent() string {
+	return tag.content
+}
+
 func (tag *Tag) GetParams() map[string]TagParam {
 	return tag.params
 }
+
+func (tag *Tag) addDuplicates(name string, params ...TagParam) {
+	if len(params) == 0 {
+		return
+	}
+	if tag.duplicates == nil {
+		tag.duplicates = make(map[string][]TagParam)
+	}
+	tag.duplicates[name] = append(tag.duplicates[name], params...)
+}
+
+// paramKey returns the key of the param name is a spelling of, which is name
+// itself unless keys are normalized.
+func (tag *Tag) paramKey(name string) (string, bool) {
+	if _, exist := tag.params[name]; exist || tag.normalize == nil {
+		return name, exist
+	}
+	key, exist := tag.keys[tag.normalize(name)]
+	return key, exist
+}
+
+func (tag *Tag) setParam(key string, param TagParam) {
+	tag.params[key] = param
+	if tag.normalize != nil {
+		if tag.keys == nil {
+			tag.keys = make(map[string]string)
+		}
+		tag.keys[tag.normalize(key)] = key
+	}
+}
+
+// index maps the normalized keys of params to their keys again.
+func (tag *Tag) index() {
+	tag.keys = nil
+	for key, param := range tag.params {
+		tag.setParam(key, param)
+	}
+}
@@ -0,0 +1,171 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	validateRulesDelimiter = ","
+	validateOrDelimiter    = "|"
+	validateParamDelimiter = "="
+	validateEscapedComma   = "0x2C"
+	validateEscapedPipe    = "0x7C"
+	validateDiveRule       = "dive"
+	validateKeysRule       = "keys"
+	validateEndKeysRule    = "endkeys"
+)
+
+// ValidateRule is a rule of a validate tag in the go-playground/validator style.
+// A rule either checks the field with Name and Param, holds alternatives in Or
+// (`a|b`), or is a dive into elements: then Keys holds the rules of map keys
+// and Dive the rules of the elements.
+type ValidateRule struct {
+	Name  string
+	Param string
+	Or    []ValidateRule
+	Keys  []ValidateRule
+	Dive  []ValidateRule
+}
+
+func (rule ValidateRule) String() string {
+	if rule.Or != nil {
+		alternatives := make([]string, len(rule.Or))
+		for i, alternative := range rule.Or {
+			alternatives[i] = alternative.String()
+		}
+		return strings.Join(alternatives, validateOrDelimiter)
+	}
+
+	if rule.Name == validateDiveRule {
+		items := []string{validateDiveRule}
+		if rule.Keys != nil {
+			items = append(items, validateKeysRule)
+			for _, key := range rule.Keys {
+				items = append(items, key.String())
+			}
+			items = append(items, validateEndKeysRule)
+		}
+		for _, elem := range rule.Dive {
+			items = append(items, elem.String())
+		}
+		return strings.Join(items, validateRulesDelimiter)
+	}
+
+	if rule.Param == "" {
+		return rule.Name
+	}
+	param := strings.NewReplacer(validateRulesDelimiter, validateEscapedComma, validateOrDelimiter, validateEscapedPipe).Replace(rule.Param)
+	return rule.Name + validateParamDelimiter + param
+}
+
+// FormatValidateTag writes rules back as the content of a validate tag.
+func FormatValidateTag(rules []ValidateRule) string {
+	items := make([]string, len(rules))
+	for i, rule := range rules {
+		items[i] = rule.String()
+	}
+	return strings.Join(items, validateRulesDelimiter)
+}
+
+// ParseValidateTag parses the content of a validate tag into its rules. Rules
+// following a dive apply to the elements and end up in the Dive of that rule.
+func ParseValidateTag(content string) ([]ValidateRule, error) {
+	rules := make([]ValidateRule, 0)
+	if content == "" {
+		return rules, nil
+	}
+
+	parser := validateParser{items: strings.Split(content, validateRulesDelimiter)}
+	return parser.rules(rules, false)
+}
+
+type validateParser struct {
+	items []string
+	pos   int
+}
+
+// rules parses items up to the end of the tag, inside keys it stops before the
+// closing endkeys.
+func (parser *validateParser) rules(rules []ValidateRule, inKeys bool) ([]ValidateRule, error) {
+	for parser.pos < len(parser.items) {
+		item := parser.items[parser.pos]
+		parser.pos++
+
+		switch item {
+		case validateDiveRule:
+			rule, err := parser.dive(inKeys)
+			if err != nil {
+				return rules, err
+			}
+			return append(rules, rule), nil
+		case validateKeysRule:
+			return rules, errors.New(fmt.Sprintf(keysWithoutDiveErr, parser.pos))
+		case validateEndKeysRule:
+			if inKeys {
+				parser.pos--
+				return rules, nil
+			} else {
+				return rules, errors.New(fmt.Sprintf(endkeysWithoutKeysErr, parser.pos))
+			}
+		default:
+			rule, err := parseValidateAlternatives(item, parser.pos)
+			if err != nil {
+				return rules, err
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (parser *validateParser) dive(inKeys bool) (ValidateRule, error) {
+	var err error
+	rule := ValidateRule{Name: validateDiveRule}
+
+	if parser.pos < len(parser.items) && parser.items[parser.pos] == validateKeysRule {
+		parser.pos++
+		keysItem := parser.pos
+
+		rule.Keys, err = parser.rules(make([]ValidateRule, 0), true)
+		if err != nil {
+			return rule, err
+		}
+		if parser.pos == len(parser.items) {
+			return rule, errors.New(fmt.Sprintf(unclosedKeysErr, keysItem))
+		}
+		parser.pos++
+	}
+
+	rule.Dive, err = parser.rules(make([]ValidateRule, 0), inKeys)
+	return rule, err
+}
+
+func parseValidateAlternatives(item string, pos int) (ValidateRule, error) {
+	if !strings.Contains(item, validateOrDelimiter) {
+		return parseValidateCheck(item, pos)
+	}
+
+	alternatives := strings.Split(item, validateOrDelimiter)
+	rule := ValidateRule{Or: make([]ValidateRule, len(alternatives))}
+	for i, alternative := range alternatives {
+		check, err := parseValidateCheck(alternative, pos)
+		if err != nil {
+			return rule, err
+		}
+		rule.Or[i] = check
+	}
+	return rule, nil
+}
+
+func parseValidateCheck(item string, pos int) (ValidateRule, error) {
+	name, param, _ := strings.Cut(item, validateParamDelimiter)
+	if name == "" {
+		return ValidateRule{}, errors.New(fmt.Sprintf(emptyValidateRuleErr, pos))
+	}
+
+	param = strings.ReplaceAll(param, validateEscapedComma, validateRulesDelimiter)
+	param = strings.ReplaceAll(param, validateEscapedPipe, validateOrDelimiter)
+	return ValidateRule{Name: name, Param: param}, nil
+}
@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	notStructErr       string = `expected a struct or a pointer to a struct, got %s`
+	unknownRuleErr     string = `unknown rule "%s" on field "%s"`
+	unsupportedTypeErr string = `rule "%s" does not support values of type %s`
+	invalidParamErr    string = `invalid param "%s" of rule "%s"`
+	fieldErr           string = `field "%s" failed on rule "%s"`
+)
+
+// FieldError reports a field value that failed a rule.
+type FieldError struct {
+	// Path locates the field from the validated struct, e.g. `Users[2].Email`.
+	Path  string
+	Rule  string
+	Param string
+	Value any
+}
+
+func (err *FieldError) Error() string {
+	rule := err.Rule
+	if err.Param != "" {
+		rule += "=" + err.Param
+	}
+	return fmt.Sprintf(fieldErr, err.Path, rule)
+}
+
+// Errors holds every failed field of a validated struct.
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
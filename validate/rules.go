@@ -0,0 +1,150 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s.]+(\.[^@\s.]+)+$`)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var builtinRules = map[string]Rule{
+	"min":   compareRule("min", true, func(order int) bool { return order >= 0 }),
+	"max":   compareRule("max", true, func(order int) bool { return order <= 0 }),
+	"len":   compareRule("len", true, func(order int) bool { return order == 0 }),
+	"eq":    compareRule("eq", false, func(order int) bool { return order == 0 }),
+	"ne":    compareRule("ne", false, func(order int) bool { return order != 0 }),
+	"gt":    compareRule("gt", true, func(order int) bool { return order > 0 }),
+	"gte":   compareRule("gte", true, func(order int) bool { return order >= 0 }),
+	"lt":    compareRule("lt", true, func(order int) bool { return order < 0 }),
+	"lte":   compareRule("lte", true, func(order int) bool { return order <= 0 }),
+	"oneof": oneOf,
+	"email": email,
+}
+
+// compareRule builds a rule comparing the value to its param. Strings, slices,
+// arrays and maps are measured by their length when byLength is set, strings are
+// compared as text otherwise. Times are compared to the param in RFC 3339, or to
+// the current time without a param.
+func compareRule(name string, byLength bool, accept func(order int) bool) Rule {
+	return func(value reflect.Value, param string) (bool, error) {
+		order, err := compare(name, value, param, byLength)
+		if err != nil {
+			return false, err
+		}
+		return accept(order), nil
+	}
+}
+
+func compare(name string, value reflect.Value, param string, byLength bool) (int, error) {
+	switch value.Kind() {
+	case reflect.String:
+		if byLength {
+			return compareInt(name, int64(utf8.RuneCountInString(value.String())), param)
+		}
+		return strings.Compare(value.String(), param), nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return compareInt(name, int64(value.Len()), param)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			duration, err := time.ParseDuration(param)
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+			}
+			return compareOrdered(value.Int(), int64(duration)), nil
+		}
+		return compareInt(name, value.Int(), param)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		limit, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+		}
+		return compareOrdered(value.Uint(), limit), nil
+	case reflect.Float32, reflect.Float64:
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+		}
+		return compareOrdered(value.Float(), limit), nil
+	case reflect.Bool:
+		if byLength {
+			break
+		}
+		expected, err := strconv.ParseBool(param)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+		}
+		if value.Bool() == expected {
+			return 0, nil
+		}
+		return 1, nil
+	case reflect.Struct:
+		if value.Type() != timeType {
+			break
+		}
+		limit := time.Now()
+		if param != "" {
+			var err error
+			if limit, err = time.Parse(time.RFC3339, param); err != nil {
+				return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+			}
+		}
+		return value.Interface().(time.Time).Compare(limit), nil
+	}
+	return 0, errors.New(fmt.Sprintf(unsupportedTypeErr, name, value.Type()))
+}
+
+func compareInt(name string, value int64, param string) (int, error) {
+	limit, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf(invalidParamErr, param, name))
+	}
+	return compareOrdered(value, limit), nil
+}
+
+func compareOrdered[T int64 | uint64 | float64](value, limit T) int {
+	if value < limit {
+		return -1
+	} else if value > limit {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+// oneOf accepts strings and integers equal to one of the space separated
+// values of param.
+func oneOf(value reflect.Value, param string) (bool, error) {
+	var text string
+	switch value.Kind() {
+	case reflect.String:
+		text = value.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		text = strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		text = strconv.FormatUint(value.Uint(), 10)
+	default:
+		return false, errors.New(fmt.Sprintf(unsupportedTypeErr, "oneof", value.Type()))
+	}
+
+	for _, option := range strings.Fields(param) {
+		if option == text {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func email(value reflect.Value, _ string) (bool, error) {
+	if value.Kind() != reflect.String {
+		return false, errors.New(fmt.Sprintf(unsupportedTypeErr, "email", value.Type()))
+	}
+	return emailRegexp.MatchString(value.String()), nil
+}
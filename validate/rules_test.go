@@ -0,0 +1,67 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	tests := []struct {
+		rule     string
+		value    any
+		param    string
+		expected bool
+	}{
+		{"min", "héllo", "5", true},
+		{"min", "héllo", "6", false},
+		{"max", []int{1, 2}, "2", true},
+		{"len", map[string]int{"a": 1}, "1", true},
+		{"len", [2]int{}, "3", false},
+		{"eq", "abc", "abc", true},
+		{"eq", "abc", "3", false},
+		{"ne", 3, "3", false},
+		{"eq", true, "true", true},
+		{"gt", uint8(200), "199", true},
+		{"gte", -1.5, "-1.5", true},
+		{"lt", int64(-2), "-1", true},
+		{"lte", float32(2.5), "2", false},
+		{"oneof", "b", "a b c", true},
+		{"oneof", "d", "a b c", false},
+		{"oneof", 7, "5 7", true},
+		{"oneof", uint(1), "2", false},
+		{"email", "a.b+c@mail.example.org", "", true},
+		{"email", "a@localhost", "", false},
+		{"email", "a b@mail.org", "", false},
+	}
+
+	for _, test := range tests {
+		passed, err := builtinRules[test.rule](reflect.ValueOf(test.value), test.param)
+		if err != nil {
+			t.Errorf("%s=%s on %#v: unexpected error: %s", test.rule, test.param, test.value, err)
+		} else if passed != test.expected {
+			t.Errorf("%s=%s on %#v: got %v, expected %v", test.rule, test.param, test.value, passed, test.expected)
+		}
+	}
+}
+
+func TestBuiltinRulesErrors(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value any
+		param string
+		err   string
+	}{
+		{"max", uint(1), "-1", `invalid param "-1" of rule "max"`},
+		{"gt", 1.5, "x", `invalid param "x" of rule "gt"`},
+		{"eq", true, "yes", `invalid param "yes" of rule "eq"`},
+		{"gt", struct{}{}, "1", `rule "gt" does not support values of type struct {}`},
+		{"oneof", 1.5, "1", `rule "oneof" does not support values of type float64`},
+		{"email", 1, "", `rule "email" does not support values of type int`},
+	}
+
+	for _, test := range tests {
+		if _, err := builtinRules[test.rule](reflect.ValueOf(test.value), test.param); err == nil || err.Error() != test.err {
+			t.Errorf("%s=%s on %#v: got error `%v`, expected `%s`", test.rule, test.param, test.value, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+// Package validate checks struct fields against the rules of their validate
+// tags, written in the go-playground/validator style and parsed by fogg.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kuzgoga/fogg"
+)
+
+const (
+	tagName       = "validate"
+	skipRule      = "-"
+	omitEmptyRule = "omitempty"
+	requiredRule  = "required"
+	diveRule      = "dive"
+)
+
+// Rule reports whether value passes the rule with param. Pointers are followed
+// before a rule is called, so value is never a pointer. An error means the rule
+// cannot be applied, e.g. its param is invalid or the value type is unsupported.
+type Rule func(value reflect.Value, param string) (bool, error)
+
+// Validator validates structs with the built-in rules and the registered ones.
+type Validator struct {
+	mutex sync.RWMutex
+	rules map[string]Rule
+	types map[reflect.Type]*structRules
+}
+
+type structRules struct {
+	fields []fieldRules
+}
+
+type fieldRules struct {
+	index int
+	name  string
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	name  string
+	param string
+	check Rule
+	or    []compiledRule
+	keys  []compiledRule
+	dive  []compiledRule
+}
+
+var defaultValidator = New()
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func New() *Validator {
+	validator := &Validator{
+		rules: make(map[string]Rule, len(builtinRules)),
+		types: make(map[reflect.Type]*structRules),
+	}
+	for name, rule := range builtinRules {
+		validator.rules[name] = rule
+	}
+	return validator
+}
+
+// Struct validates v with the default validator.
+func Struct(v any) error {
+	return defaultValidator.Struct(v)
+}
+
+// RegisterRule adds a rule to the default validator.
+func RegisterRule(name string, rule Rule) {
+	defaultValidator.RegisterRule(name, rule)
+}
+
+// RegisterRule adds a rule or replaces the rule with the same name.
+func (validator *Validator) RegisterRule(name string, rule Rule) {
+	validator.mutex.Lock()
+	defer validator.mutex.Unlock()
+
+	validator.rules[name] = rule
+	validator.types = make(map[reflect.Type]*structRules)
+}
+
+// Struct validates the fields of the struct v points to or holds, nested structs
+// included. Failed fields are returned as Errors, other errors mean the rules
+// cannot be applied.
+func (validator *Validator) Struct(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf(notStructErr, reflect.TypeOf(v)))
+	}
+
+	var failures Errors
+	if err := validator.validateStruct(value, "", &failures); err != nil {
+		return err
+	}
+	if len(failures) != 0 {
+		return failures
+	}
+	return nil
+}
+
+func (validator *Validator) structRules(structType reflect.Type) (*structRules, error) {
+	validator.mutex.RLock()
+	rules, exists := validator.types[structType]
+	validator.mutex.RUnlock()
+	if exists {
+		return rules, nil
+	}
+
+	validator.mutex.Lock()
+	defer validator.mutex.Unlock()
+
+	if rules, exists := validator.types[structType]; exists {
+		return rules, nil
+	}
+
+	rules = &structRules{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		content := field.Tag.Get(tagName)
+		if content == skipRule {
+			continue
+		}
+
+		parsed, err := fogg.ParseValidateTag(content)
+		if err != nil {
+			return nil, err
+		}
+		compiled, err := validator.compile(parsed, field.Name)
+		if err != nil {
+			return nil, err
+		}
+		rules.fields = append(rules.fields, fieldRules{index: i, name: field.Name, rules: compiled})
+	}
+
+	validator.types[structType] = rules
+	return rules, nil
+}
+
+func (validator *Validator) compile(rules []fogg.ValidateRule, fieldName string) ([]compiledRule, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, rule := range rules {
+		var err error
+		compiled[i] = compiledRule{name: rule.Name, param: rule.Param}
+
+		switch {
+		case rule.Or != nil:
+			compiled[i].name = rule.String()
+			compiled[i].or, err = validator.compileAlternatives(rule.Or, fieldName)
+		case rule.Name == diveRule:
+			if rule.Keys != nil {
+				if compiled[i].keys, err = validator.compile(rule.Keys, fieldName); err != nil {
+					return nil, err
+				}
+			}
+			compiled[i].dive, err = validator.compile(rule.Dive, fieldName)
+		case rule.Name == omitEmptyRule || rule.Name == requiredRule:
+		default:
+			check, exists := validator.rules[rule.Name]
+			if !exists {
+				return nil, errors.New(fmt.Sprintf(unknownRuleErr, rule.Name, fieldName))
+			}
+			compiled[i].check = check
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+// compileAlternatives compiles the rules of an `a|b` alternation, which cannot
+// dive.
+func (validator *Validator) compileAlternatives(rules []fogg.ValidateRule, fieldName string) ([]compiledRule, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, rule := range rules {
+		compiled[i] = compiledRule{name: rule.Name, param: rule.Param}
+		if rule.Name == omitEmptyRule || rule.Name == requiredRule {
+			continue
+		}
+
+		check, exists := validator.rules[rule.Name]
+		if !exists {
+			return nil, errors.New(fmt.Sprintf(unknownRuleErr, rule.Name, fieldName))
+		}
+		compiled[i].check = check
+	}
+	return compiled, nil
+}
+
+func (validator *Validator) validateStruct(value reflect.Value, path string, failures *Errors) error {
+	rules, err := validator.structRules(value.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range rules.fields {
+		fieldPath := field.name
+		if path != "" {
+			fieldPath = path + "." + field.name
+		}
+		if err := validator.validateValue(value.Field(field.index), fieldPath, field.rules, failures); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue applies rules to value and stops at its first failed rule, as
+// validator does. Struct values are validated field by field once their own
+// rules pass.
+func (validator *Validator) validateValue(value reflect.Value, path string, rules []compiledRule, failures *Errors) error {
+	for _, rule := range rules {
+		switch {
+		case rule.or == nil && rule.name == omitEmptyRule:
+			if !hasValue(value) {
+				return nil
+			}
+		case rule.or == nil && rule.name == diveRule:
+			return validator.dive(value, path, rule, failures)
+		default:
+			passed, err := checkRule(rule, value)
+			if err != nil {
+				return err
+			}
+			if !passed {
+				*failures = append(*failures, &FieldError{Path: path, Rule: rule.name, Param: rule.param, Value: value.Interface()})
+				return nil
+			}
+		}
+	}
+
+	if value, ok := indirect(value); ok && value.Kind() == reflect.Struct && value.Type() != timeType {
+		return validator.validateStruct(value, path, failures)
+	}
+	return nil
+}
+
+func (validator *Validator) dive(value reflect.Value, path string, rule compiledRule, failures *Errors) error {
+	value, ok := indirect(value)
+	if !ok {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elemPath := path + "[" + strconv.Itoa(i) + "]"
+			if err := validator.validateValue(value.Index(i), elemPath, rule.dive, failures); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		paths := make([]string, len(keys))
+		for i, key := range keys {
+			paths[i] = fmt.Sprintf("%s[%v]", path, key.Interface())
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return paths[order[i]] < paths[order[j]]
+		})
+
+		for _, i := range order {
+			if err := validator.validateValue(keys[i], paths[i], rule.keys, failures); err != nil {
+				return err
+			}
+			if err := validator.validateValue(value.MapIndex(keys[i]), paths[i], rule.dive, failures); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New(fmt.Sprintf(unsupportedTypeErr, diveRule, value.Type()))
+	}
+	return nil
+}
+
+func checkRule(rule compiledRule, value reflect.Value) (bool, error) {
+	if rule.or != nil {
+		for _, alternative := range rule.or {
+			if passed, err := checkRule(alternative, value); passed || err != nil {
+				return passed, err
+			}
+		}
+		return false, nil
+	}
+
+	if rule.name == requiredRule {
+		return hasValue(value), nil
+	} else if rule.name == omitEmptyRule {
+		return !hasValue(value), nil
+	}
+
+	value, ok := indirect(value)
+	if !ok {
+		return false, nil
+	}
+	return rule.check(value, rule.param)
+}
+
+// hasValue follows validator: nil-able values are set when they are not nil,
+// other values when they are not zero.
+func hasValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface, reflect.Chan, reflect.Func:
+		return !value.IsNil()
+	default:
+		return value.IsValid() && !value.IsZero()
+	}
+}
+
+// indirect follows pointers and interfaces, it reports false on nil.
+func indirect(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, value.IsValid()
+}
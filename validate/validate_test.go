@@ -0,0 +1,243 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `validate:"required,min=2"`
+	Zip  string `validate:"omitempty,len=5"`
+}
+
+type user struct {
+	Name     string            `validate:"required,max=8"`
+	Email    string            `validate:"email"`
+	Age      int               `validate:"gte=18,lt=130"`
+	Role     string            `validate:"oneof=admin user"`
+	Tags     []string          `validate:"max=3,dive,required"`
+	Labels   map[string]int    `validate:"dive,keys,min=2,endkeys,ne=0"`
+	Address  address           `validate:"required"`
+	Backup   *address          `validate:"omitempty"`
+	Contacts []address         `validate:"dive"`
+	Color    string            `validate:"omitempty,eq=red|eq=blue"`
+	Internal string            `validate:"-"`
+	Extra    map[string]string ``
+}
+
+func validUser() user {
+	return user{
+		Name:    "ann",
+		Email:   "ann@example.com",
+		Age:     30,
+		Role:    "admin",
+		Tags:    []string{"a"},
+		Labels:  map[string]int{"ab": 1},
+		Address: address{City: "Rome"},
+	}
+}
+
+func failedPaths(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+
+	failures, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	paths := make([]string, len(failures))
+	for i, failure := range failures {
+		paths[i] = failure.Path + " " + failure.Rule
+	}
+	return paths
+}
+
+func TestStruct(t *testing.T) {
+	valid := validUser()
+	if err := Struct(&valid); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	invalid := validUser()
+	invalid.Name = ""
+	invalid.Email = "ann@"
+	invalid.Age = 17
+	invalid.Role = "root"
+	invalid.Tags = []string{"a", ""}
+	invalid.Labels = map[string]int{"a": 1, "bc": 0}
+	invalid.Address = address{City: "R", Zip: "123"}
+	invalid.Backup = &address{}
+	invalid.Contacts = []address{{City: "Oslo"}, {City: "Bergen", Zip: "1"}}
+	invalid.Color = "green"
+	invalid.Internal = "anything"
+
+	expected := []string{
+		"Name required",
+		"Email email",
+		"Age gte",
+		"Role oneof",
+		"Tags[1] required",
+		"Labels[a] min",
+		"Labels[bc] ne",
+		"Address.City min",
+		"Address.Zip len",
+		"Backup.City required",
+		"Contacts[1].Zip len",
+		"Color eq=red|eq=blue",
+	}
+	if paths := failedPaths(t, Struct(invalid)); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got failures %q, expected %q", paths, expected)
+	}
+}
+
+func TestStructErrorMessage(t *testing.T) {
+	invalid := validUser()
+	invalid.Name = "a very long name"
+	invalid.Age = 200
+
+	err := Struct(invalid)
+	expected := `field "Name" failed on rule "max=8"; field "Age" failed on rule "lt=130"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("got error `%v`, expected `%s`", err, expected)
+	}
+
+	failures := err.(Errors)
+	if failures[0].Value != "a very long name" || failures[1].Param != "130" {
+		t.Errorf("unexpected failures %+v, %+v", failures[0], failures[1])
+	}
+}
+
+func TestStructTime(t *testing.T) {
+	type event struct {
+		Start    time.Time     `validate:"gt"`
+		End      time.Time     `validate:"lt=2030-01-01T00:00:00Z"`
+		Duration time.Duration `validate:"min=1m,max=1h"`
+	}
+
+	valid := event{Start: time.Now().Add(time.Hour), End: time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC), Duration: time.Minute}
+	if err := Struct(valid); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	invalid := event{Start: time.Now().Add(-time.Hour), End: time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC), Duration: 2 * time.Hour}
+	expected := []string{"Start gt", "End lt", "Duration max"}
+	if paths := failedPaths(t, Struct(invalid)); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got failures %q, expected %q", paths, expected)
+	}
+}
+
+func TestStructPointers(t *testing.T) {
+	type pointers struct {
+		Required *int `validate:"required"`
+		Min      *int `validate:"omitempty,min=5"`
+	}
+
+	zero, six := 0, 6
+	if err := Struct(pointers{Required: &zero, Min: &six}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	expected := []string{"Required required", "Min min"}
+	if paths := failedPaths(t, Struct(pointers{Min: &zero})); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got failures %q, expected %q", paths, expected)
+	}
+}
+
+func TestStructInvalid(t *testing.T) {
+	tests := []struct {
+		value any
+		err   string
+	}{
+		{42, `expected a struct or a pointer to a struct, got int`},
+		{(*user)(nil), `expected a struct or a pointer to a struct, got *validate.user`},
+		{struct {
+			A int `validate:"unknown"`
+		}{}, `unknown rule "unknown" on field "A"`},
+		{struct {
+			A int `validate:"required|dive"`
+		}{}, `unknown rule "dive" on field "A"`},
+		{struct {
+			A int `validate:"min=x"`
+		}{}, `invalid param "x" of rule "min"`},
+		{struct {
+			A bool `validate:"min=1"`
+		}{}, `rule "min" does not support values of type bool`},
+		{struct {
+			A int `validate:"dive"`
+		}{}, `rule "dive" does not support values of type int`},
+		{struct {
+			A []int `validate:"dive,keys,min=1,endkeys"`
+		}{}, ``},
+		{struct {
+			A int `validate:"dive,keys"`
+		}{}, `"keys" at item 2 of validate tag is not closed by "endkeys"`},
+	}
+
+	for _, test := range tests {
+		err := Struct(test.value)
+		if test.err == "" && err == nil {
+			continue
+		}
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Struct(%#v): got error `%v`, expected `%s`", test.value, err, test.err)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	type document struct {
+		Title string `validate:"prefix=doc:"`
+	}
+
+	validator := New()
+	if err := validator.Struct(document{}); err == nil || !strings.Contains(err.Error(), `unknown rule "prefix"`) {
+		t.Errorf("expected unknown rule error, got %v", err)
+	}
+
+	validator.RegisterRule("prefix", func(value reflect.Value, param string) (bool, error) {
+		return strings.HasPrefix(value.String(), param), nil
+	})
+	if err := validator.Struct(document{Title: "doc:readme"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"Title prefix"}
+	if paths := failedPaths(t, validator.Struct(document{Title: "readme"})); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got failures %q, expected %q", paths, expected)
+	}
+}
+
+func TestStructCachesRules(t *testing.T) {
+	validator := New()
+	value := validUser()
+
+	if err := validator.Struct(value); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cached := validator.types[reflect.TypeOf(value)]
+	if cached == nil || validator.types[reflect.TypeOf(address{})] == nil {
+		t.Fatalf("expected rules of validated types to be cached")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = validator.Struct(&value)
+	})
+	if validator.types[reflect.TypeOf(value)] != cached {
+		t.Errorf("expected cached rules to be reused")
+	}
+	if allocs > 20 {
+		t.Errorf("expected validation of a cached type to allocate little, got %v allocations", allocs)
+	}
+}
+
+func BenchmarkStruct(b *testing.B) {
+	value := validUser()
+	for i := 0; i < b.N; i++ {
+		if err := Struct(&value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package fogg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []ValidateRule
+	}{
+		{``, []ValidateRule{}},
+		{`required`, []ValidateRule{{Name: "required"}}},
+		{`required,min=1,max=64,oneof=a b c`, []ValidateRule{
+			{Name: "required"},
+			{Name: "min", Param: "1"},
+			{Name: "max", Param: "64"},
+			{Name: "oneof", Param: "a b c"},
+		}},
+		{`rgb|rgba|hsl=`, []ValidateRule{
+			{Or: []ValidateRule{{Name: "rgb"}, {Name: "rgba"}, {Name: "hsl"}}},
+		}},
+		{`excludesall=0x2C0x7C!,contains=a=b`, []ValidateRule{
+			{Name: "excludesall", Param: ",|!"},
+			{Name: "contains", Param: "a=b"},
+		}},
+		{`required,dive,keys,eq=x,endkeys,required`, []ValidateRule{
+			{Name: "required"},
+			{Name: "dive", Keys: []ValidateRule{{Name: "eq", Param: "x"}}, Dive: []ValidateRule{{Name: "required"}}},
+		}},
+		{`dive,keys,endkeys`, []ValidateRule{
+			{Name: "dive", Keys: []ValidateRule{}, Dive: []ValidateRule{}},
+		}},
+		{`min=1,dive,min=2,dive,keys,len=3|eq=,endkeys,max=4`, []ValidateRule{
+			{Name: "min", Param: "1"},
+			{Name: "dive", Dive: []ValidateRule{
+				{Name: "min", Param: "2"},
+				{Name: "dive",
+					Keys: []ValidateRule{{Or: []ValidateRule{{Name: "len", Param: "3"}, {Name: "eq"}}}},
+					Dive: []ValidateRule{{Name: "max", Param: "4"}},
+				},
+			}},
+		}},
+		{`dive,keys,dive,required,endkeys,omitempty`, []ValidateRule{
+			{Name: "dive",
+				Keys: []ValidateRule{{Name: "dive", Dive: []ValidateRule{{Name: "required"}}}},
+				Dive: []ValidateRule{{Name: "omitempty"}},
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		rules, err := ParseValidateTag(test.content)
+		if err != nil {
+			t.Errorf("ParseValidateTag(%q): unexpected error: %s", test.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(rules, test.expected) {
+			t.Errorf("ParseValidateTag(%q) = %+v; want %+v", test.content, rules, test.expected)
+		}
+	}
+}
+
+func TestParseValidateTagErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{`required,`, `empty rule at item 2 of validate tag`},
+		{`required,,min=1`, `empty rule at item 2 of validate tag`},
+		{`a|=1`, `empty rule at item 1 of validate tag`},
+		{`required,keys,endkeys`, `"keys" at item 2 of validate tag must follow "dive"`},
+		{`dive,required,keys`, `"keys" at item 3 of validate tag must follow "dive"`},
+		{`required,endkeys`, `"endkeys" at item 2 of validate tag has no matching "keys"`},
+		{`dive,keys,required`, `"keys" at item 2 of validate tag is not closed by "endkeys"`},
+		{`dive,keys,eq=1,endkeys,endkeys`, `"endkeys" at item 5 of validate tag has no matching "keys"`},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseValidateTag(test.content); err == nil || err.Error() != test.err {
+			t.Errorf("ParseValidateTag(%q): got error `%v`, expected `%s`", test.content, err, test.err)
+		}
+	}
+}
+
+func TestFormatValidateTag(t *testing.T) {
+	contents := []string{
+		`required,min=1,max=64,oneof=a b c,dive,keys,eq=x,endkeys`,
+		`excludesall=0x2C0x7C!,rgb|rgba`,
+		`dive,keys,endkeys`,
+		`dive,dive,required`,
+	}
+
+	for _, content := range contents {
+		rules, err := ParseValidateTag(content)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+		if formatted := FormatValidateTag(rules); formatted != content {
+			t.Errorf("FormatValidateTag(ParseValidateTag(%q)) = %q", content, formatted)
+		}
+	}
+}
+
+func TestParseValidateDialect(t *testing.T) {
+	storage, err := Parse(`validate:"required,oneof=a b;c,dive,keys,eq=x,endkeys"`, WithDialects())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tag := storage.GetTag("validate")
+	expected := []string{"required", "oneof=a b;c", "dive", "keys", "eq=x", "endkeys"}
+	if !reflect.DeepEqual(tag.GetOptions(), expected) || tag.GetValue() != "required" {
+		t.Errorf("unexpected validate tag %+v", tag)
+	}
+
+	for _, content := range []string{"dive,keys", "required,,min=1"} {
+		storage, err := Parse(`json:"id" validate:"`+content+`"`, WithDialects())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", content, err)
+		} else if storage.GetTag("validate").GetContent() != content {
+			t.Errorf("%q: got content %q", content, storage.GetTag("validate").GetContent())
+		}
+		if _, err := ParseValidateTag(content); err == nil {
+			t.Errorf("%q: expected ParseValidateTag to fail", content)
+		}
+	}
+}
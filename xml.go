@@ -0,0 +1,206 @@
+package fogg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	xmlNamespaceDelimiter = " "
+	xmlOptionsDelimiter   = ","
+	xmlParentsDelimiter   = ">"
+	xmlNameField          = "XMLName"
+)
+
+type XMLKind int
+
+const (
+	XMLElement XMLKind = iota
+	XMLAttr
+	XMLCData
+	XMLCharData
+	XMLInnerXML
+	XMLComment
+	// XMLAny is an element matching any element, XMLAnyAttr is set by the
+	// `any,attr` options and matches any attribute.
+	XMLAny
+	XMLAnyAttr
+)
+
+// XMLField describes a struct field the way encoding/xml resolves its tag.
+type XMLField struct {
+	Namespace string
+	// Name is the local name of the element or attribute. ParseXMLField leaves
+	// it empty when the tag has no name, XMLFieldOf then applies the defaults.
+	Name string
+	// Parents holds the elements of an `a>b>c` chain enclosing the field.
+	Parents   []string
+	Kind      XMLKind
+	OmitEmpty bool
+}
+
+// ParseXMLField parses the content of an xml tag.
+func ParseXMLField(content string) (XMLField, error) {
+	return parseXMLField(content, false)
+}
+
+func parseXMLField(content string, xmlName bool) (XMLField, error) {
+	field := XMLField{}
+
+	name := content
+	if namespace, rest, found := strings.Cut(name, xmlNamespaceDelimiter); found {
+		field.Namespace, name = namespace, rest
+	}
+
+	if name, options, found := strings.Cut(name, xmlOptionsDelimiter); found {
+		if err := field.parseOptions(name, options, xmlName, content); err != nil {
+			return field, err
+		}
+	}
+	name, _, _ = strings.Cut(name, xmlOptionsDelimiter)
+
+	if field.Namespace != "" && name == "" {
+		return field, errors.New(fmt.Sprintf(xmlNamespaceErr, content))
+	}
+	if xmlName || name == "" {
+		field.Name = name
+		return field, nil
+	}
+
+	parents := strings.Split(name, xmlParentsDelimiter)
+	if parents[len(parents)-1] == "" {
+		return field, errors.New(fmt.Sprintf(xmlTrailingParentErr, content))
+	}
+	field.Name = parents[len(parents)-1]
+	if len(parents) > 1 {
+		if field.Kind != XMLElement && field.Kind != XMLAny {
+			return field, errors.New(fmt.Sprintf(xmlParentsErr, content))
+		}
+		field.Parents = parents[:len(parents)-1]
+	}
+
+	return field, nil
+}
+
+// parseOptions follows encoding/xml: a field takes at most one kind, only
+// attributes may name themselves next to their kind, and only elements and
+// attributes may be omitted when empty.
+func (field *XMLField) parseOptions(name, options string, xmlName bool, content string) error {
+	kinds := map[XMLKind]bool{}
+	for _, option := range strings.Split(options, xmlOptionsDelimiter) {
+		switch option {
+		case "attr":
+			kinds[XMLAttr] = true
+		case "cdata":
+			kinds[XMLCData] = true
+		case "chardata":
+			kinds[XMLCharData] = true
+		case "innerxml":
+			kinds[XMLInnerXML] = true
+		case "comment":
+			kinds[XMLComment] = true
+		case "any":
+			kinds[XMLAny] = true
+		case "omitempty":
+			field.OmitEmpty = true
+		}
+	}
+
+	if kinds[XMLAny] && kinds[XMLAttr] {
+		// any and attr together are a single kind
+		delete(kinds, XMLAny)
+		delete(kinds, XMLAttr)
+		kinds[XMLAnyAttr] = true
+	}
+	if len(kinds) > 1 {
+		return errors.New(fmt.Sprintf(xmlInvalidTagErr, content))
+	}
+	for kind := range kinds {
+		field.Kind = kind
+	}
+
+	if len(kinds) > 0 && (xmlName || name != "" && field.Kind != XMLAttr) {
+		return errors.New(fmt.Sprintf(xmlInvalidTagErr, content))
+	}
+	if field.OmitEmpty && field.Kind != XMLElement && field.Kind != XMLAny && field.Kind != XMLAttr && field.Kind != XMLAnyAttr {
+		return errors.New(fmt.Sprintf(xmlInvalidTagErr, content))
+	}
+	return nil
+}
+
+// XMLFieldOf resolves the xml tag of a struct field as encoding/xml does: an
+// empty name defaults to the XMLName of the field type or to the Go name. It
+// reports false when encoding/xml skips the field or embeds the fields of an
+// anonymous struct.
+func XMLFieldOf(field reflect.StructField) (XMLField, bool, error) {
+	content := field.Tag.Get("xml")
+	if (!field.IsExported() && !field.Anonymous) || content == "-" {
+		return XMLField{}, false, nil
+	}
+
+	if field.Anonymous {
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if embedded.Kind() == reflect.Struct {
+			return XMLField{}, false, nil
+		}
+	}
+
+	xmlField, err := parseXMLField(content, field.Name == xmlNameField)
+	if err != nil || field.Name == xmlNameField {
+		return xmlField, true, err
+	}
+
+	typeName, hasTypeName := lookupXMLName(field.Type)
+	if xmlField.Name == "" {
+		if hasTypeName {
+			xmlField.Namespace, xmlField.Name = typeName.Namespace, typeName.Name
+		} else {
+			xmlField.Name = field.Name
+		}
+		return xmlField, true, nil
+	}
+
+	if len(xmlField.Parents) > 0 && xmlField.Parents[0] == "" {
+		xmlField.Parents[0] = field.Name
+	}
+
+	isElement := xmlField.Kind == XMLElement || xmlField.Kind == XMLAny
+	if isElement && hasTypeName && typeName.Name != xmlField.Name {
+		return xmlField, true, errors.New(fmt.Sprintf(xmlNameConflictErr, xmlField.Name, field.Name, typeName.Name, field.Type))
+	}
+
+	return xmlField, true, nil
+}
+
+// lookupXMLName returns the name set by the XMLName field of a struct type.
+func lookupXMLName(fieldType reflect.Type) (XMLField, bool) {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct {
+		return XMLField{}, false
+	}
+
+	nameField, exists := fieldType.FieldByName(xmlNameField)
+	if !exists || len(nameField.Index) != 1 {
+		return XMLField{}, false
+	}
+
+	typeName, err := parseXMLField(nameField.Tag.Get("xml"), true)
+	if err != nil || typeName.Name == "" {
+		return XMLField{}, false
+	}
+	return typeName, true
+}
+
+// parseXMLSubtag parses xml tags in the classic style. They are checked to be
+// valid for encoding/xml by ParseXMLField and XMLFieldOf, as encoding/xml only
+// reports invalid tags when marshaling.
+func parseXMLSubtag(content string) (Tag, error) {
+	return parseClassicSubtag(content)
+}
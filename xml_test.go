@@ -0,0 +1,168 @@
+package fogg
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestParseXMLField(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected XMLField
+	}{
+		{``, XMLField{}},
+		{`name`, XMLField{Name: "name"}},
+		{`urn:x name,omitempty`, XMLField{Namespace: "urn:x", Name: "name", OmitEmpty: true}},
+		{`id,attr`, XMLField{Name: "id", Kind: XMLAttr}},
+		{`,attr,omitempty`, XMLField{Kind: XMLAttr, OmitEmpty: true}},
+		{`,chardata`, XMLField{Kind: XMLCharData}},
+		{`,cdata`, XMLField{Kind: XMLCData}},
+		{`,innerxml`, XMLField{Kind: XMLInnerXML}},
+		{`,comment`, XMLField{Kind: XMLComment}},
+		{`,any`, XMLField{Kind: XMLAny}},
+		{`,any,attr`, XMLField{Kind: XMLAnyAttr}},
+		{`a>b>c,omitempty`, XMLField{Name: "c", Parents: []string{"a", "b"}, OmitEmpty: true}},
+		{`>c`, XMLField{Name: "c", Parents: []string{""}}},
+		{`name,unknown`, XMLField{Name: "name"}},
+	}
+
+	for _, test := range tests {
+		field, err := ParseXMLField(test.content)
+		if err != nil {
+			t.Errorf("ParseXMLField(%q): unexpected error: %s", test.content, err)
+		} else if !reflect.DeepEqual(field, test.expected) {
+			t.Errorf("ParseXMLField(%q) = %+v; want %+v", test.content, field, test.expected)
+		}
+	}
+}
+
+type xmlNamed struct {
+	XMLName xml.Name `xml:"urn:n named"`
+	Value   string   `xml:",chardata"`
+}
+
+func TestXMLFieldOfMatchesEncodingXML(t *testing.T) {
+	tests := []struct {
+		tag      reflect.StructTag
+		value    any
+		expected string
+	}{
+		{``, "v", `<s><Field>v</Field></s>`},
+		{`xml:"name"`, "v", `<s><name>v</name></s>`},
+		{`xml:"urn:x name"`, "v", `<s><name xmlns="urn:x">v</name></s>`},
+		{`xml:"name,omitempty"`, "", `<s></s>`},
+		{`xml:"id,attr"`, "v", `<s id="v"></s>`},
+		{`xml:",attr"`, "v", `<s Field="v"></s>`},
+		{`xml:",attr,omitempty"`, "", `<s></s>`},
+		{`xml:",chardata"`, "v", `<s>v</s>`},
+		{`xml:",cdata"`, "v", `<s><![CDATA[v]]></s>`},
+		{`xml:",innerxml"`, "<x/>", `<s><x/></s>`},
+		{`xml:",comment"`, "v", `<s><!--v--></s>`},
+		{`xml:"a>b>c"`, "v", `<s><a><b><c>v</c></b></a></s>`},
+		{`xml:">c"`, "v", `<s><Field><c>v</c></Field></s>`},
+		{`xml:"-"`, "v", `<s></s>`},
+		{`xml:"-,"`, "v", `<s><->v</-></s>`},
+		{`xml:""`, xmlNamed{Value: "v"}, `<s><named xmlns="urn:n">v</named></s>`},
+		{`xml:"named"`, xmlNamed{Value: "v"}, `<s><named xmlns="urn:n">v</named></s>`},
+		{`xml:"other"`, xmlNamed{}, ``},
+		{`xml:"urn:x"`, "v", `<s><urn:x>v</urn:x></s>`},
+		{`xml:"urn:x "`, "v", ``},
+		{`xml:"urn:x ,attr"`, "v", ``},
+		{`xml:"name,chardata"`, "v", ``},
+		{`xml:",attr,chardata"`, "v", ``},
+		{`xml:"name,any"`, "v", ``},
+		{`xml:"name,any,attr"`, "v", ``},
+		{`xml:",any,attr,chardata"`, "v", ``},
+		{`xml:",attr,any,comment"`, "v", ``},
+		{`xml:",comment,omitempty"`, "v", ``},
+		{`xml:"a>"`, "v", ``},
+		{`xml:"a>b,attr"`, "v", ``},
+	}
+
+	for _, test := range tests {
+		structType := reflect.StructOf([]reflect.StructField{
+			{Name: "XMLName", Type: reflect.TypeOf(xml.Name{}), Tag: `xml:"s"`},
+			{Name: "Field", Type: reflect.TypeOf(test.value), Tag: test.tag},
+		})
+		structValue := reflect.New(structType).Elem()
+		structValue.Field(1).Set(reflect.ValueOf(test.value))
+
+		data, marshalErr := xml.Marshal(structValue.Interface())
+		_, _, err := XMLFieldOf(structType.Field(1))
+
+		if (err != nil) != (marshalErr != nil) {
+			t.Errorf("tag %s: XMLFieldOf error `%v`, encoding/xml error `%v`", test.tag, err, marshalErr)
+		} else if marshalErr == nil && string(data) != test.expected {
+			t.Errorf("tag %s: encoding/xml gives %s, expected %s", test.tag, data, test.expected)
+		}
+	}
+}
+
+type xmlEmbedded struct {
+	Inner string
+}
+
+type xmlResolution struct {
+	XMLName xml.Name `xml:"urn:r root"`
+	xmlEmbedded
+	hidden  string
+	Skipped string    `xml:"-"`
+	Named   xmlNamed  `xml:""`
+	Pointer *xmlNamed `xml:"named,omitempty"`
+	Chain   string    `xml:">leaf"`
+}
+
+func TestXMLFieldOf(t *testing.T) {
+	expected := []struct {
+		field XMLField
+		ok    bool
+	}{
+		{XMLField{Namespace: "urn:r", Name: "root"}, true},
+		{XMLField{}, false},
+		{XMLField{}, false},
+		{XMLField{}, false},
+		{XMLField{Namespace: "urn:n", Name: "named"}, true},
+		{XMLField{Name: "named", OmitEmpty: true}, true},
+		{XMLField{Name: "leaf", Parents: []string{"Chain"}}, true},
+	}
+
+	structType := reflect.TypeOf(xmlResolution{})
+	for i, test := range expected {
+		field, ok, err := XMLFieldOf(structType.Field(i))
+		if err != nil {
+			t.Errorf("field %s: unexpected error: %s", structType.Field(i).Name, err)
+		} else if ok != test.ok || !reflect.DeepEqual(field, test.field) {
+			t.Errorf("field %s: got %+v, %v; want %+v, %v", structType.Field(i).Name, field, ok, test.field, test.ok)
+		}
+	}
+}
+
+func TestParseXMLDialect(t *testing.T) {
+	storage, err := Parse(`xml:"urn:x name,attr,omitempty"`, WithDialects())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tag := storage.GetTag("xml")
+	if tag.GetValue() != "urn:x name" || !tag.HasOption("attr") || !tag.HasOption("omitempty") {
+		t.Errorf("unexpected xml tag %+v", tag)
+	}
+
+	invalid := map[string]string{
+		"a>b,attr":        `parents chain of xml tag "a>b,attr" is only valid for elements`,
+		"a,attr,chardata": `invalid xml tag "a,attr,chardata"`,
+	}
+	for content, expected := range invalid {
+		storage, err := Parse(`json:"id" xml:"`+content+`"`, WithDialects())
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", content, err)
+		} else if storage.GetTag("xml").GetContent() != content {
+			t.Errorf("%q: got content %q", content, storage.GetTag("xml").GetContent())
+		}
+
+		field := reflect.StructField{Name: "A", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`xml:"` + content + `"`)}
+		if _, _, err := XMLFieldOf(field); err == nil || err.Error() != expected {
+			t.Errorf("%q: got error %v from XMLFieldOf, expected %q", content, err, expected)
+		}
+	}
+}